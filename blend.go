@@ -0,0 +1,112 @@
+package gograph
+
+import (
+    "math"
+    "image/color"
+)
+
+/*
+    How a color is combined with the
+    color already underneath it when drawn
+*/
+type BlendMode int
+
+const (
+    /* The color is simply drawn over what is underneath it */
+    BlendNormal BlendMode = iota
+
+    /* Multiplies the colors, which always darkens */
+    BlendMultiply
+
+    /* Multiplies the inverses of the colors, which always lightens */
+    BlendScreen
+
+    /* Adds the colors together */
+    BlendAdd
+
+    /* Subtracts the darker color from the lighter one */
+    BlendDifference
+
+    /* Takes the bitwise exclusive or of the colors */
+    BlendXor
+)
+
+/*
+    A color that will be drawn using a BlendMode.
+    It can be passed to any of the drawing functions
+    of a Graph to pick the BlendMode for that call.
+*/
+type BlendedColor struct {
+    color.Color
+
+    Mode BlendMode
+}
+
+func WithBlendMode(col color.Color, mode BlendMode) BlendedColor {
+    return BlendedColor{col, mode}
+}
+
+/*
+    Blends two channels that are not premultiplied,
+    with each between 0 and 1.
+*/
+func blendChannel(mode BlendMode, old, new float64) float64 {
+    switch mode {
+        case BlendMultiply:
+            return old * new
+
+        case BlendScreen:
+            return old + new - old * new
+
+        case BlendAdd:
+            return math.Min(old + new, 1)
+
+        case BlendDifference:
+            return math.Abs(old - new)
+
+        case BlendXor:
+            return float64(uint16(old * 0xFFFF) ^ uint16(new * 0xFFFF)) / 0xFFFF
+    }
+
+    return new
+}
+
+/*
+    Draws a new color over an old one using the given BlendMode.
+    Where both colors are opaque the result is entirely the
+    blended color, and elsewhere it fades into each color alone.
+*/
+func BlendColorWithMode(old, new color.Color, mode BlendMode) color.Color {
+    if mode == BlendNormal {
+        return BlendColor(old, new)
+    }
+
+    old_r, old_g, old_b, old_a := old.RGBA()
+    new_r, new_g, new_b, new_a := new.RGBA()
+
+    if new_a == 0 {
+        return old
+    }
+
+    alpha_old, alpha_new := float64(old_a) / 0xFFFF, float64(new_a) / 0xFFFF
+
+    channel := func (old_c, new_c uint32) uint16 {
+        premul_old, premul_new := float64(old_c) / 0xFFFF, float64(new_c) / 0xFFFF
+
+        blended := 0.0
+        if old_a != 0 {
+            blended = blendChannel(mode, premul_old / alpha_old, premul_new / alpha_new)
+        }
+
+        c := premul_new * (1 - alpha_old) + premul_old * (1 - alpha_new) + alpha_old * alpha_new * blended
+
+        return uint16(math.Round(math.Min(c, 1) * 0xFFFF))
+    }
+
+    return color.RGBA64{
+        channel(old_r, new_r),
+        channel(old_g, new_g),
+        channel(old_b, new_b),
+        uint16(math.Round((alpha_new + alpha_old * (1 - alpha_new)) * 0xFFFF)),
+    }
+}
\ No newline at end of file
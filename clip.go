@@ -0,0 +1,98 @@
+package gograph
+
+import "image"
+
+/*
+    Clip masks restrict where a Graph can be drawn on.
+    While a mask is pushed, SetPixel will only change
+    pixels inside of it, and so everything drawn using
+    SetPixel will be restricted to it as well. Pushing
+    another mask restricts drawing to the intersection
+    of it and the masks already pushed.
+
+    Masks are made of the pixels that were inside
+    when they were pushed, so a mask isn't affected
+    by anything that changes after it was pushed.
+*/
+
+func (g *Graph) PushClipMaskInChunk(inside func (pt image.Point) bool, mask []bool, r *image.Rectangle, ch chan struct{}) {
+    top := g.clipMask()
+
+    for x := r.Min.X; x < r.Max.X; x++ {
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            i := y * g.ImageWidth() + x
+
+            mask[i] = (top == nil || top[i]) && inside(image.Pt(x, y))
+        }
+    }
+
+    ch <- struct{}{}
+}
+
+/* Pushes a mask of the pixels for which inside returns true */
+func (g *Graph) PushClipMask(inside func (pt image.Point) bool) {
+    mask := make([]bool, g.ImageWidth() * g.ImageHeight())
+
+    var channels []chan struct{}
+
+    for x := 0; x < g.ImageWidth(); x += ChunkSize {
+        for y := 0; y < g.ImageHeight(); y += ChunkSize {
+            ch := make(chan struct {})
+            channels = append(channels, ch)
+
+            r := image.Rect(x, y, MinInt(x + ChunkSize, g.ImageWidth()), MinInt(y + ChunkSize, g.ImageHeight()))
+            go g.PushClipMaskInChunk(inside, mask, &r, ch)
+        }
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+
+    g.clips = append(g.clips, mask)
+}
+
+/*
+    Pushes a mask of the region inside a Relation,
+    using IsInside to decide what is inside it.
+*/
+func (g *Graph) PushClipRelation(rel Relation) {
+    g.PushClipMask(func (pt image.Point) bool {
+        return IsInside(rel(g.PixelToCoord(pt)))
+    })
+}
+
+func (g *Graph) PushClipArea(a *Area) {
+    g.PushClipMask(func (pt image.Point) bool {
+        return a.Contains(g.PixelToCoord(pt))
+    })
+}
+
+/* Removes the most recently pushed mask */
+func (g *Graph) PopClip() {
+    if len(g.clips) > 0 {
+        g.clips = g.clips[:len(g.clips) - 1]
+    }
+}
+
+func (g *Graph) clipMask() []bool {
+    if len(g.clips) == 0 {
+        return nil
+    }
+
+    return g.clips[len(g.clips) - 1]
+}
+
+/* Whether a pixel is outside of the current clip mask */
+func (g *Graph) IsClipped(pt image.Point) bool {
+    mask := g.clipMask()
+    if mask == nil {
+        return false
+    }
+
+    if !pt.In(g.Image.Bounds()) {
+        return true
+    }
+
+    return !mask[pt.Y * g.ImageWidth() + pt.X]
+}
\ No newline at end of file
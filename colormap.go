@@ -0,0 +1,106 @@
+package gograph
+
+import (
+    "math"
+    "image/color"
+)
+
+/* A function that maps a value between 0 and 1 to a color */
+type Colormap func (t float64) color.Color
+
+var (
+    /* Goes from black to white */
+    GrayscaleColormap = GradientColormap(color.Black, color.White)
+
+    /* The perceptually uniform colormap from matplotlib */
+    ViridisColormap = GradientColormap(
+        color.RGBA{0x44, 0x01, 0x54, 0xFF},
+        color.RGBA{0x48, 0x28, 0x78, 0xFF},
+        color.RGBA{0x3E, 0x4A, 0x89, 0xFF},
+        color.RGBA{0x31, 0x68, 0x8E, 0xFF},
+        color.RGBA{0x26, 0x82, 0x8E, 0xFF},
+        color.RGBA{0x1F, 0x9E, 0x89, 0xFF},
+        color.RGBA{0x35, 0xB7, 0x79, 0xFF},
+        color.RGBA{0x6D, 0xCD, 0x59, 0xFF},
+        color.RGBA{0xB4, 0xDE, 0x2C, 0xFF},
+        color.RGBA{0xFD, 0xE7, 0x25, 0xFF},
+    )
+
+    /* Goes from black through red and yellow to white */
+    HotColormap = GradientColormap(
+        color.Black,
+        color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+        color.RGBA{0xFF, 0xFF, 0x00, 0xFF},
+        color.White,
+    )
+)
+
+/* Linearly interpolates between two colors */
+func LerpColor(c0, c1 color.Color, t float64) color.Color {
+    r0, g0, b0, a0 := c0.RGBA()
+    r1, g1, b1, a1 := c1.RGBA()
+
+    lerp := func (v0, v1 uint32) uint16 {
+        return uint16(math.Round(float64(v0) + (float64(v1) - float64(v0)) * t))
+    }
+
+    return color.RGBA64{lerp(r0, r1), lerp(g0, g1), lerp(b0, b1), lerp(a0, a1)}
+}
+
+/* A colormap that goes between evenly spaced colors */
+func GradientColormap(cols ...color.Color) Colormap {
+    return func (t float64) color.Color {
+        if math.IsNaN(t) {
+            return color.Transparent
+        }
+
+        t = math.Max(0, math.Min(1, t)) * float64(len(cols) - 1)
+
+        i := MinInt(int(t), len(cols) - 2)
+        if i < 0 {
+            return cols[0]
+        }
+
+        return LerpColor(cols[i], cols[i + 1], t - float64(i))
+    }
+}
+
+/*
+    Gets a color from its hue, saturation, and lightness,
+    which all go from 0 to 1. A hue of 0 is red, and the
+    hue goes around through green and blue back to red at 1.
+*/
+func HSLColor(hue, saturation, lightness float64) color.Color {
+    hue = (hue - math.Floor(hue)) * 6
+    chroma := (1 - math.Abs(2 * lightness - 1)) * saturation
+    x := chroma * (1 - math.Abs(math.Mod(hue, 2) - 1))
+
+    var r, g, b float64
+    switch int(hue) {
+        case 0:
+            r, g, b = chroma, x, 0
+
+        case 1:
+            r, g, b = x, chroma, 0
+
+        case 2:
+            r, g, b = 0, chroma, x
+
+        case 3:
+            r, g, b = 0, x, chroma
+
+        case 4:
+            r, g, b = x, 0, chroma
+
+        default:
+            r, g, b = chroma, 0, x
+    }
+
+    m := lightness - chroma / 2
+
+    channel := func (c float64) uint16 {
+        return uint16(math.Round(math.Max(0, math.Min(1, c + m)) * 0xFFFF))
+    }
+
+    return color.RGBA64{channel(r), channel(g), channel(b), 0xFFFF}
+}
\ No newline at end of file
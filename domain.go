@@ -0,0 +1,146 @@
+package gograph
+
+import (
+    "math"
+    "math/cmplx"
+    "image"
+    "image/color"
+)
+
+/* The default color of the contour lines of domain colorings */
+var DefaultContourColor = color.RGBA{0x00, 0x00, 0x00, 0x80}
+
+/*
+    How a ComplexRelation is drawn with domain coloring.
+
+    If ModulusBase isn't 0, contour lines are drawn where the
+    modulus is a power of it. If PhaseContours isn't 0, that many
+    contour lines are drawn where the phase is evenly spaced
+    around the circle, starting with the positive real numbers.
+    The contour lines are ContourWidth pixels wide.
+*/
+type DomainColoringStyle struct {
+    ModulusBase float64
+    PhaseContours int
+
+    ContourColor color.Color
+    ContourWidth float64
+}
+
+func NewDomainColoringStyle(modulus_base float64, phase_contours int) *DomainColoringStyle {
+    return &DomainColoringStyle{modulus_base, phase_contours, DefaultContourColor, 1}
+}
+
+/*
+    Gets the color of a complex number, with its phase as
+    the hue and its modulus as the lightness, so that zeros
+    are black, poles are white, and the modulus of 1 has the
+    most vivid colors.
+*/
+func DomainColor(z complex128) color.Color {
+    if cmplx.IsInf(z) {
+        return color.White
+    }
+
+    return HSLColor(cmplx.Phase(z) / (2 * math.Pi), 1, 2 / math.Pi * math.Atan(cmplx.Abs(z)))
+}
+
+/*
+    Gets how much of a pixel is covered by the contour lines
+    of a value, which are where it is a whole number. The
+    neighboring values are used to find how far away the
+    nearest contour line is in pixels. If period isn't 0,
+    the value wraps around to 0 once it reaches period.
+*/
+func contourCoverage(val, right, down, period, width float64) float64 {
+    d_right, d_down := right - val, down - val
+
+    if period != 0 {
+        d_right = math.Remainder(d_right, period)
+        d_down = math.Remainder(d_down, period)
+    }
+
+    slope := math.Hypot(d_right, d_down)
+    if slope == 0 || math.IsNaN(slope) || math.IsInf(slope, 0) {
+        return 0
+    }
+
+    return edgeCoverage(width / 2, distToMultiple(val, 1) / slope)
+}
+
+func (g *Graph) DrawDomainColoringInChunk(f ComplexRelation, style *DomainColoringStyle, r *image.Rectangle, ch chan struct{}) {
+    eval := func (pt image.Point) complex128 {
+        c := g.PixelToCoord(pt)
+
+        return f(complex(c.X, c.Y))
+    }
+
+    for x := r.Min.X; x < r.Max.X; x++ {
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            pt := image.Pt(x, y)
+
+            z := eval(pt)
+            if cmplx.IsNaN(z) {
+                continue
+            }
+
+            g.SetPixel(pt, DomainColor(z))
+
+            if (style.ModulusBase != 0 || style.PhaseContours != 0) && !cmplx.IsInf(z) {
+                right, down := eval(pt.Add(image.Pt(1, 0))), eval(pt.Add(image.Pt(0, 1)))
+                coverage := 0.0
+
+                if style.ModulusBase != 0 {
+                    modulus := func (z complex128) float64 {
+                        return math.Log(cmplx.Abs(z)) / math.Log(style.ModulusBase)
+                    }
+
+                    coverage = contourCoverage(modulus(z), modulus(right), modulus(down), 0, style.ContourWidth)
+                }
+
+                if style.PhaseContours != 0 {
+                    turns := float64(style.PhaseContours)
+
+                    phase := func (z complex128) float64 {
+                        return cmplx.Phase(z) / (2 * math.Pi) * turns
+                    }
+
+                    coverage = math.Max(coverage, contourCoverage(phase(z), phase(right), phase(down), turns, style.ContourWidth))
+                }
+
+                g.SetPixelWithCoverage(pt, style.ContourColor, coverage)
+            }
+        }
+    }
+
+    ch <- struct{}{}
+}
+
+/*
+    Draws a ComplexRelation by coloring each point of the graph
+    by the value of the relation there, using DomainColor.
+    Zeros show up as black points that the colors go around
+    counterclockwise, poles as white points that they go around
+    clockwise, and branch cuts as lines where the colors jump.
+*/
+func (g *Graph) DrawDomainColoringWithStyle(f ComplexRelation, style *DomainColoringStyle) {
+    var channels []chan struct{}
+
+    for x := 0; x < g.ImageWidth(); x += ChunkSize {
+        for y := 0; y < g.ImageHeight(); y += ChunkSize {
+            ch := make(chan struct{})
+            channels = append(channels, ch)
+
+            r := image.Rect(x, y, MinInt(x + ChunkSize, g.ImageWidth()), MinInt(y + ChunkSize, g.ImageHeight()))
+            go g.DrawDomainColoringInChunk(f, style, &r, ch)
+        }
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+}
+
+func (g *Graph) DrawDomainColoring(f ComplexRelation) {
+    g.DrawDomainColoringWithStyle(f, NewDomainColoringStyle(0, 0))
+}
\ No newline at end of file
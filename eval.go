@@ -176,6 +176,30 @@ func Eval(expr string) (interface{}, error) {
             }), nil
         }
 
+        if is_func, dep_first := IsFunction("x", "y", vars0, vars1, tokens0, tokens1); is_func {
+            e := e1
+            if !dep_first {
+                e = e0
+            }
+
+            return SidewaysFunction(func (y float64) float64 {
+                params := map[string]interface{} {
+                    "y": y,
+                }
+
+                for k, v := range Constants {
+                    params[k] = v
+                }
+
+                result, err := e.Evaluate(params)
+                if err != nil {
+                    return math.NaN()
+                }
+
+                return result.(float64)
+            }), nil
+        }
+
         if is_func, dep_first := IsFunction("r", "theta", vars0, vars1, tokens0, tokens1); is_func {
             e := e1
             if !dep_first {
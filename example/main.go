@@ -56,6 +56,9 @@ func main() {
             case gograph.Function:
                 g.DrawFunctionWithColor(expr.(gograph.Function), col)
 
+            case gograph.SidewaysFunction:
+                g.DrawSidewaysFunctionWithColor(expr.(gograph.SidewaysFunction), col)
+
             case gograph.PolarFunction:
                 g.DrawPolarFunctionWithColor(expr.(gograph.PolarFunction), col)
 
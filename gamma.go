@@ -0,0 +1,82 @@
+package gograph
+
+import (
+    "math"
+    "sync"
+    "image/color"
+)
+
+var (
+    /* Lookup tables for converting 16-bit channels between sRGB and linear light */
+    srgbToLinear, linearToSrgb [0x10000]uint16
+
+    gammaTablesOnce sync.Once
+)
+
+func initGammaTables() {
+    for i := range srgbToLinear {
+        c := float64(i) / 0xFFFF
+
+        if c <= 0.04045 {
+            c /= 12.92
+        } else {
+            c = math.Pow((c + 0.055) / 1.055, 2.4)
+        }
+
+        srgbToLinear[i] = uint16(math.Round(c * 0xFFFF))
+    }
+
+    for i := range linearToSrgb {
+        c := float64(i) / 0xFFFF
+
+        if c <= 0.0031308 {
+            c *= 12.92
+        } else {
+            c = 1.055 * math.Pow(c, 1 / 2.4) - 0.055
+        }
+
+        linearToSrgb[i] = uint16(math.Round(c * 0xFFFF))
+    }
+}
+
+/*
+    Converts the channels of a premultiplied color
+    using a lookup table, which must be done on the
+    channels before they are premultiplied.
+*/
+func convertPremultiplied(col color.Color, table *[0x10000]uint16) color.RGBA64 {
+    r, g, b, a := col.RGBA()
+    if a == 0 {
+        return color.RGBA64{}
+    }
+
+    /* Colors that aren't properly premultiplied can have channels larger than their alpha */
+    channel := func (c uint32) uint16 {
+        return uint16(uint32(table[minUint32(c, a) * 0xFFFF / a]) * a / 0xFFFF)
+    }
+
+    return color.RGBA64{channel(r), channel(g), channel(b), uint16(a)}
+}
+
+/* Converts an sRGB color into linear light */
+func ToLinear(col color.Color) color.RGBA64 {
+    gammaTablesOnce.Do(initGammaTables)
+
+    return convertPremultiplied(col, &srgbToLinear)
+}
+
+/* Converts a color in linear light into sRGB */
+func FromLinear(col color.Color) color.RGBA64 {
+    gammaTablesOnce.Do(initGammaTables)
+
+    return convertPremultiplied(col, &linearToSrgb)
+}
+
+/*
+    Like BlendColorWithMode, but blends the colors in
+    linear light instead of with their sRGB values, which
+    keeps blended colors from looking too dark.
+*/
+func BlendColorLinear(old, new color.Color, mode BlendMode) color.Color {
+    return FromLinear(BlendColorWithMode(ToLinear(old), ToLinear(new), mode))
+}
\ No newline at end of file
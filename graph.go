@@ -22,10 +22,66 @@ const (
     AngleSize = math.Pi / 4
 
     /*
-        The angle step that goroutines will increment
-        the angle by when drawing polar functions
+        The angle step that goroutines will start with
+        when drawing polar functions, before adjusting it
+        to the length of the curve being drawn
     */
     AngleStep = AngleSize / 100
+
+    /*
+        The most times that the space between two
+        pixel columns will be subdivided when drawing
+        functions
+    */
+    MaxFunctionDepth = 10
+
+    /*
+        The least times that the space between two
+        pixel columns will be subdivided when drawing
+        functions, so that fast oscillations aren't missed
+    */
+    MinFunctionDepth = 2
+
+    /*
+        How far in pixels a function can stray from a
+        straight line before it will be subdivided
+    */
+    FunctionTolerance = 0.5
+
+    /*
+        How much of a jump between two samples of a function
+        needs to remain after subdividing them once more for it
+        to be treated as a discontinuity instead of a steep slope
+    */
+    JumpRatio = 0.9
+
+    /*
+        The number of chunks that goroutines will
+        split the range of t values into when drawing
+        parametric functions
+    */
+    ParametricChunks = 16
+
+    /*
+        The number of samples over the range of t
+        values that parametric functions start with
+    */
+    ParametricSamples = 1024
+
+    /*
+        The range of distances in pixels that steps along
+        a parametric function will be kept within
+    */
+    MinParametricStep = 0.5
+    MaxParametricStep = 2.0
+)
+
+const (
+    /* Points with a negative radius are reflected through the pole */
+    ReflectNegativeRadius NegativeRadius = iota
+
+    /* Points with a negative radius are not drawn */
+    SkipNegativeRadius
 )
 
 var (
@@ -82,6 +138,16 @@ type Relation func (c *Coord) interface{}
 */
 type Function func (x float64) float64
 
+/*
+    A function that takes in a y value
+    and returns an x value. This is what
+    you want for relations of the form
+    "x == f(y)" as it will be drawn faster
+    and more accurately than using the
+    Relation version.
+*/
+type SidewaysFunction func (y float64) float64
+
 /*
     A function that takes in a theta value
     and returns a radius value. This is what
@@ -92,6 +158,19 @@ type Function func (x float64) float64
 */
 type PolarFunction func (theta float64) float64
 
+/* How points of a PolarFunction with a negative radius are drawn */
+type NegativeRadius int
+
+/*
+    A function that takes in a parameter value
+    and returns a coordinate. This is what you
+    want for curves of the form "(x(t), y(t))",
+    such as Lissajous figures and cycloids, which
+    would be slow and inaccurate to draw using
+    the Relation version.
+*/
+type ParametricFunction func (t float64) *Coord
+
 /*
     A function that takes is a complex number
     and returns a complex number. This is used
@@ -107,6 +186,49 @@ type ComplexRelation func (z complex128) complex128
 */
 type DifferentialFunction func (c *Coord) float64
 
+/*
+    A function that takes in an x value along
+    with the values of y and its derivatives
+    at that x value, starting with y itself,
+    and returns the next derivative. This is
+    what you want for differential equations
+    of the form "y'' == f(x, y, y')", such as
+    damped oscillators and Airy's equation.
+*/
+type HigherOrderFunction func (x float64, y []float64) float64
+
+/*
+    A function that takes in a coordinate and
+    returns the vector of a vector field at that
+    point, with the vector's components as the
+    x and y values of the returned coordinate.
+*/
+type VectorField func (c *Coord) *Coord
+
+/*
+    A function that takes in a coordinate and
+    returns how fast the coordinate changes over
+    time, for systems of differential equations of
+    the form "dx/dt == P(x, y), dy/dt == Q(x, y)",
+    with P and Q as the x and y values of the
+    returned coordinate.
+*/
+type System func (c *Coord) *Coord
+
+/*
+    How the area between two functions gets filled.
+    Above is used where the first function is greater
+    than the second, and Below is used where it is less.
+    Either may be nil to leave that part unfilled.
+
+    If MeasureArea is set, the signed area between
+    the functions will be calculated as well.
+*/
+type FillStyle struct {
+    Above, Below color.Color
+    MeasureArea bool
+}
+
 type InvalidAreaError struct{}
 
 /*
@@ -120,11 +242,28 @@ type Area struct {
 
 type InvalidScaleError struct{}
 
+/*
+    A graph drawn onto an image. Image is the image
+    of the layer currently being drawn on, and all
+    of the Layers are composited together when saved.
+
+    If LinearLight is set, colors will be blended
+    in linear light instead of in sRGB.
+
+    If Supersampling is set, boolean Relations and
+    ComplexRelations will be drawn with it.
+*/
 type Graph struct {
     Bounds *Area
     Image *image.RGBA
+    Layers []*Layer
 
     BackgroundColor, RelationColor, AxisColor, GridColor color.Color
+    LinearLight bool
+    Supersampling *Supersampling
+
+    layer *Layer
+    clips [][]bool
 }
 
 func NewCoord(x, y float64) *Coord {
@@ -155,6 +294,15 @@ func (c *Coord) Div(div float64) *Coord {
     return NewCoord(c.X / div, c.Y / div)
 }
 
+/* Swaps the x and y values */
+func (c *Coord) Transpose() *Coord {
+    return NewCoord(c.Y, c.X)
+}
+
+func (c *Coord) Dot(other *Coord) float64 {
+    return c.X * other.X + c.Y * other.Y
+}
+
 func (c *Coord) Dist(other *Coord) float64 {
     return math.Sqrt(math.Pow(c.X - other.X, 2) + math.Pow(c.Y - other.Y, 2))
 }
@@ -199,6 +347,25 @@ func (f Function) ToRelation() Relation {
     }
 }
 
+/* Converts a polar function to the curve it traces out as theta changes */
+func (f PolarFunction) ToParametric(negative NegativeRadius) ParametricFunction {
+    return func (theta float64) *Coord {
+        r := f(theta)
+
+        if r < 0 && negative == SkipNegativeRadius {
+            return NewCoord(math.NaN(), math.NaN())
+        }
+
+        return NewCoordFromPolar(r, theta)
+    }
+}
+
+func (f SidewaysFunction) ToRelation() Relation {
+    return func (c *Coord) interface{} {
+        return c.X - f(c.Y)
+    }
+}
+
 func (f PolarFunction) ToRelation() Relation {
     return func (c *Coord) interface{} {
         r, theta := c.Polar()
@@ -252,6 +419,55 @@ func (a *Area) Contains(c *Coord) bool {
     return a.Pos0.X <= c.X && c.X < a.Pos1.X && a.Pos0.Y >= c.Y && c.Y > a.Pos1.Y
 }
 
+/*
+    Clips the line between two coordinates to the area,
+    returning false if none of the line is inside it.
+*/
+func (a *Area) ClipLine(c0, c1 *Coord) (*Coord, *Coord, bool) {
+    t0, t1 := 0.0, 1.0
+    delta := c1.Sub(c0)
+
+    edges := [4][2]float64 {
+        {-delta.X, c0.X - a.Pos0.X},
+        {delta.X, a.Pos1.X - c0.X},
+        {-delta.Y, c0.Y - a.Pos1.Y},
+        {delta.Y, a.Pos0.Y - c0.Y},
+    }
+
+    for _, edge := range edges {
+        p, q := edge[0], edge[1]
+
+        if p == 0 {
+            if q < 0 {
+                return nil, nil, false
+            }
+
+            continue
+        }
+
+        t := q / p
+        if p < 0 {
+            t0 = math.Max(t0, t)
+        } else {
+            t1 = math.Min(t1, t)
+        }
+
+        if t0 > t1 {
+            return nil, nil, false
+        }
+    }
+
+    if t1 < 1 {
+        c1 = c0.Add(delta.Mult(t1))
+    }
+
+    if t0 > 0 {
+        c0 = c0.Add(delta.Mult(t0))
+    }
+
+    return c0, c1, true
+}
+
 func (e InvalidScaleError) Error() string {
     return "Invalid scale"
 }
@@ -266,6 +482,10 @@ func NewGraphWithColors(bounds *Area, scale float64, bg_col, rel_col, axis_col,
     g.Bounds = bounds
     g.Image = image.NewRGBA(image.Rect(0, 0, int(bounds.Width() * scale), int(bounds.Height() * scale)))
 
+    g.NewLayer(BackgroundLayer)
+    g.NewLayer(DefaultLayer)
+    g.UseLayer(BackgroundLayer)
+
     g.BackgroundColor = bg_col
     g.RelationColor = rel_col
     g.AxisColor = axis_col
@@ -277,6 +497,8 @@ func NewGraphWithColors(bounds *Area, scale float64, bg_col, rel_col, axis_col,
         }
     }
 
+    g.UseLayer(DefaultLayer)
+
     return g, nil
 }
 
@@ -285,7 +507,7 @@ func NewGraph(bounds *Area, scale float64) (*Graph, error) {
 }
 
 func (g *Graph) SavePNG(w io.Writer) error {
-    return png.Encode(w, g.Image)
+    return png.Encode(w, g.Composite())
 }
 
 func (g *Graph) ImageWidth() int {
@@ -297,15 +519,34 @@ func (g *Graph) ImageHeight() int {
 }
 
 func (g *Graph) CoordToPixel(c *Coord) image.Point {
+    tmp_c := g.CoordToSubpixel(c)
+
+    return image.Pt(int(tmp_c.X), int(tmp_c.Y))
+}
+
+/* Like CoordToPixel, but without rounding to a whole pixel */
+func (g *Graph) CoordToSubpixel(c *Coord) *Coord {
     tmp_c := c.Sub(g.Bounds.Pos0)
     tmp_c.X *= float64(g.ImageWidth()) / g.Bounds.Width()
     tmp_c.Y *= -float64(g.ImageHeight()) / g.Bounds.Height()
 
-    return image.Pt(int(tmp_c.X), int(tmp_c.Y))
+    return tmp_c
+}
+
+/* The bounds grown by a pixel on each side */
+func (g *Graph) PaddedBounds() *Area {
+    pad := NewCoord(g.Bounds.Width() / float64(g.ImageWidth()), -g.Bounds.Height() / float64(g.ImageHeight()))
+
+    return &Area{g.Bounds.Pos0.Sub(pad), g.Bounds.Pos1.Add(pad)}
 }
 
 func (g *Graph) PixelToCoord(pt image.Point) *Coord {
-    c := NewCoord(float64(pt.X), float64(pt.Y))
+    return g.SubpixelToCoord(float64(pt.X), float64(pt.Y))
+}
+
+/* Like PixelToCoord, but for a position between pixels */
+func (g *Graph) SubpixelToCoord(x, y float64) *Coord {
+    c := NewCoord(x, y)
     c.X *= g.Bounds.Width() / float64(g.ImageWidth())
     c.Y *= -g.Bounds.Height() / float64(g.ImageHeight())
     c = c.Add(g.Bounds.Pos0)
@@ -314,7 +555,39 @@ func (g *Graph) PixelToCoord(pt image.Point) *Coord {
 }
 
 func (g *Graph) SetPixel(pt image.Point, col color.Color) {
-    g.Image.Set(pt.X, pt.Y, BlendColor(g.Image.At(pt.X, pt.Y), col))
+    g.SetPixelWithCoverage(pt, col, 1)
+}
+
+/*
+    Sets a pixel that is only partially covered
+    by what is being drawn, making the color
+    as transparent as the pixel is uncovered.
+*/
+func (g *Graph) SetPixelWithCoverage(pt image.Point, col color.Color, coverage float64) {
+    if !(coverage > 0) || g.IsClipped(pt) {
+        return
+    }
+
+    mode := BlendNormal
+    if b, ok := col.(BlendedColor); ok {
+        col, mode = b.Color, b.Mode
+    }
+
+    if p, ok := col.(Pattern); ok {
+        col = p.ColorAt(g, pt)
+    }
+
+    if coverage < 1 {
+        col = ScaleAlpha(col, coverage)
+    }
+
+    old := g.Image.At(pt.X, pt.Y)
+
+    if g.LinearLight {
+        g.Image.Set(pt.X, pt.Y, BlendColorLinear(old, col, mode))
+    } else {
+        g.Image.Set(pt.X, pt.Y, BlendColorWithMode(old, col, mode))
+    }
 }
 
 func (g *Graph) SetCoord(c *Coord, col color.Color) {
@@ -336,6 +609,12 @@ func (g *Graph) DrawLine(c0, c1 *Coord, col color.Color) {
         return
     }
 
+    /* Keep lines that go far off the graph from taking forever to draw */
+    c0, c1, ok := g.PaddedBounds().ClipLine(c0, c1)
+    if !ok {
+        return
+    }
+
     var p0, p1 image.Point
 
     if (c0.X <= c1.X) {
@@ -392,6 +671,70 @@ func (g *Graph) DrawLine(c0, c1 *Coord, col color.Color) {
     }
 }
 
+/* Draws a filled dot with a radius in pixels */
+func (g *Graph) DrawDot(c *Coord, radius float64, col color.Color) {
+    g.DrawRing(c, 0, radius, col)
+}
+
+/*
+    Calls fn with each pixel of a ring between two radii
+    in pixels, along with how much of the pixel it covers.
+*/
+func (g *Graph) ringPixels(c *Coord, inner, outer float64, fn func (pt image.Point, coverage float64)) {
+    if !c.IsValid() {
+        return
+    }
+
+    center := g.CoordToSubpixel(c)
+    r := int(math.Ceil(outer)) + 1
+
+    for x := int(center.X) - r; x <= int(center.X) + r; x++ {
+        for y := int(center.Y) - r; y <= int(center.Y) + r; y++ {
+            dist := NewCoord(float64(x) + 0.5, float64(y) + 0.5).Dist(center)
+
+            /* Treat the ring as a line centered between the radii */
+            fn(image.Pt(x, y), edgeCoverage((outer - inner) / 2, math.Abs(dist - (outer + inner) / 2)))
+        }
+    }
+}
+
+/*
+    Draws a ring between two radii in pixels,
+    with the edges of the ring anti-aliased.
+*/
+func (g *Graph) DrawRing(c *Coord, inner, outer float64, col color.Color) {
+    g.ringPixels(c, inner, outer, func (pt image.Point, coverage float64) {
+        g.SetPixelWithCoverage(pt, col, coverage)
+    })
+}
+
+/*
+    Clears part of a pixel of the current layer,
+    so that the layers below it show through.
+*/
+func (g *Graph) ClearPixelWithCoverage(pt image.Point, coverage float64) {
+    if !(coverage > 0) || g.IsClipped(pt) {
+        return
+    }
+
+    g.Image.Set(pt.X, pt.Y, ScaleAlpha(g.Image.At(pt.X, pt.Y), 1 - math.Min(coverage, 1)))
+}
+
+/*
+    Clears a dot with a radius in pixels out of the current
+    layer, such as for the hole of a hollow dot, which stays
+    see-through even when the background is transparent.
+*/
+func (g *Graph) ClearDot(c *Coord, radius float64) {
+    g.ringPixels(c, 0, radius, g.ClearPixelWithCoverage)
+}
+
+/* Draws a dot with a hole in it, so the outline is width pixels wide */
+func (g *Graph) DrawHollowDot(c *Coord, radius, width float64, col color.Color) {
+    g.ClearDot(c, radius - width)
+    g.DrawRing(c, radius - width, radius, col)
+}
+
 func (g *Graph) DrawAxes() {
     g.DrawLine(NewCoord(0, g.Bounds.Pos0.Y), NewCoord(0, g.Bounds.Pos1.Y), g.AxisColor)
     g.DrawLine(NewCoord(g.Bounds.Pos0.X, 0), NewCoord(g.Bounds.Pos1.X, 0), g.AxisColor)
@@ -419,10 +762,22 @@ func (g *Graph) DrawRelationInChunk(rel Relation, r *image.Rectangle, col color.
 
             switch ret := rel(c); ret.(type) {
                 case bool:
-                    if ret.(bool) {
-                        g.SetPixel(pt, col)
+                    if g.Supersampling == nil {
+                        if ret.(bool) {
+                            g.SetPixel(pt, col)
+                        }
+
+                        break
                     }
 
+                    coverage, err := g.relationCoverage(rel, pt, ret.(bool))
+                    if err != nil {
+                        ch <- struct{}{}
+                        return
+                    }
+
+                    g.SetPixelWithCoverage(pt, col, coverage)
+
                 case float64:
                     if math.IsNaN(ret.(float64)) || math.IsInf(ret.(float64), 1) || math.IsInf(ret.(float64), -1) {
                         break
@@ -480,6 +835,10 @@ func (g *Graph) DrawRelationWithColor(rel Relation, col color.Color) {
     }
 }
 
+func (g *Graph) DrawRelationWithPattern(rel Relation, pat Pattern) {
+    g.DrawRelationWithColor(rel, pat)
+}
+
 func (g *Graph) DrawRelation(rel Relation) {
     g.DrawRelationWithColor(rel, g.RelationColor)
 }
@@ -506,13 +865,13 @@ func (g *Graph) ApplyComplexRelationInChunk(rel ComplexRelation, dst *image.RGBA
 }
 
 func (g *Graph) ApplyComplexRelation(rel ComplexRelation) {
-    img := image.NewRGBA(g.Image.Bounds())
-    for x := 0; x < g.ImageWidth(); x++ {
-        for y := 0; y < g.ImageHeight(); y++ {
-            g.SetPixel(image.Pt(x, y), g.BackgroundColor)
-        }
+    if g.Supersampling != nil {
+        g.ApplyComplexRelationSupersampled(rel)
+        return
     }
 
+    img := image.NewRGBA(g.Image.Bounds())
+
     var channels []chan struct{}
 
     for x := 0; x < g.ImageWidth(); x += ChunkSize {
@@ -530,25 +889,32 @@ func (g *Graph) ApplyComplexRelation(rel ComplexRelation) {
     }
 
     g.Image = img
+    g.layer.Image = img
 }
 
-func (g *Graph) DrawDifferentialFunctionInDirection(d DifferentialFunction, start *Coord, dx float64, col color.Color, ch chan struct{}) {
-    old := start
-
-    for i := 0; i < g.ImageWidth(); i++ {
-        start = start.Add(NewCoord(dx, d(start) * dx))
-        g.DrawLine(start, old, col)
-        old = start
-
-        if !g.Bounds.Contains(start) {
-            break
-        }
+/*
+    Draws the solution of a differential function through a
+    point in one direction, solving it with an integrator in
+    steps that start at dx, until it reaches the edge of the graph.
+*/
+func (g *Graph) DrawDifferentialFunctionInDirectionWithIntegrator(d DifferentialFunction, start *Coord, dx float64, integ Integrator, col color.Color, ch chan struct{}) {
+    end := math.Max(g.Bounds.Pos1.X, start.X)
+    if dx < 0 {
+        end = math.Min(g.Bounds.Pos0.X, start.X)
     }
 
+    g.DrawODESolution(d.ToODE(), integ, start.X, end, []float64{start.Y}, math.Abs(dx), func (x float64, y []float64) *Coord {
+        return NewCoord(x, y[0])
+    }, col)
+
     ch <- struct{}{}
 }
 
-func (g *Graph) DrawDifferentialFunctionWithColor(d DifferentialFunction, start *Coord, col color.Color) {
+func (g *Graph) DrawDifferentialFunctionInDirection(d DifferentialFunction, start *Coord, dx float64, col color.Color, ch chan struct{}) {
+    g.DrawDifferentialFunctionInDirectionWithIntegrator(d, start, dx, DefaultIntegrator, col, ch)
+}
+
+func (g *Graph) DrawDifferentialFunctionWithIntegrator(d DifferentialFunction, start *Coord, integ Integrator, col color.Color) {
     channels := [2]chan struct{} {
         make(chan struct{}),
         make(chan struct{}),
@@ -556,18 +922,93 @@ func (g *Graph) DrawDifferentialFunctionWithColor(d DifferentialFunction, start
 
     dx := g.Bounds.Width() / float64(g.ImageWidth())
 
-    go g.DrawDifferentialFunctionInDirection(d, start, dx, col, channels[0])
-    go g.DrawDifferentialFunctionInDirection(d, start, -dx, col, channels[1])
+    go g.DrawDifferentialFunctionInDirectionWithIntegrator(d, start, dx, integ, col, channels[0])
+    go g.DrawDifferentialFunctionInDirectionWithIntegrator(d, start, -dx, integ, col, channels[1])
 
     for _, ch := range channels {
         <-ch
     }
 }
 
+func (g *Graph) DrawDifferentialFunctionWithColor(d DifferentialFunction, start *Coord, col color.Color) {
+    g.DrawDifferentialFunctionWithIntegrator(d, start, DefaultIntegrator, col)
+}
+
 func (g *Graph) DrawDifferentialFunction(d DifferentialFunction, start *Coord) {
     g.DrawDifferentialFunctionWithColor(d, start, g.RelationColor)
 }
 
+/*
+    Draws a function between two samples of it, subdividing
+    wherever it bends too much to be drawn as a straight line.
+    Nothing is drawn where the function is undefined or where
+    it jumps, such as at the poles of tan(x).
+
+    The samples have the input of the function as their X
+    and its output as their Y. If sideways is set, the input
+    is a y value on the graph and the output is an x value.
+*/
+func (g *Graph) drawSamplesBetween(f func (float64) float64, s0, s1 *Coord, sideways bool, depth int, parent_jump float64, col color.Color) {
+    /* Gets the coordinate on the graph and how far along the output axis it is in pixels */
+    place := func (s *Coord) (*Coord, float64) {
+        if sideways {
+            c := s.Transpose()
+            return c, g.CoordToSubpixel(c).X
+        }
+
+        return s, g.CoordToSubpixel(s).Y
+    }
+
+    c0, out0 := place(s0)
+    c1, out1 := place(s1)
+    valid0, valid1 := c0.IsValid(), c1.IsValid()
+
+    /* Nothing between the points will be seen if they're both off the same side */
+    if depth >= MinFunctionDepth && valid0 && valid1 && g.PaddedBounds().OffSameSide(c0, c1) {
+        return
+    }
+
+    jump := math.Abs(out1 - out0)
+
+    if depth >= MaxFunctionDepth {
+        if valid0 && valid1 && (jump <= 2 * FunctionTolerance || jump < JumpRatio * parent_jump) {
+            g.DrawLine(c0, c1, col)
+        }
+
+        return
+    }
+
+    mid_in := (s0.X + s1.X) / 2
+    mid := NewCoord(mid_in, f(mid_in))
+
+    /* The function is undefined between the points */
+    if !valid0 && !valid1 && !mid.IsValid() {
+        return
+    }
+
+    if valid0 && valid1 && mid.IsValid() && depth >= MinFunctionDepth {
+        _, out_mid := place(mid)
+
+        if math.Abs(out_mid - (out0 + out1) / 2) <= FunctionTolerance {
+            g.DrawLine(c0, c1, col)
+            return
+        }
+    }
+
+    g.drawSamplesBetween(f, s0, mid, sideways, depth + 1, jump, col)
+    g.drawSamplesBetween(f, mid, s1, sideways, depth + 1, jump, col)
+}
+
+/*
+    Draws a function between two points on it, subdividing
+    wherever it bends too much to be drawn as a straight line.
+    Nothing is drawn where the function is undefined or where
+    it jumps, such as at the poles of tan(x).
+*/
+func (g *Graph) DrawFunctionBetween(f Function, c0, c1 *Coord, col color.Color) {
+    g.drawSamplesBetween(f, c0, c1, false, 0, math.Inf(1), col)
+}
+
 func (g *Graph) DrawFunctionInRange(f Function, start, end int, col color.Color, ch chan struct{}) {
     real_start := g.PixelToCoord(image.Pt(start, 0)).X
     old := NewCoord(real_start, f(real_start))
@@ -576,7 +1017,7 @@ func (g *Graph) DrawFunctionInRange(f Function, start, end int, col color.Color,
         real_x := g.PixelToCoord(image.Pt(x, 0)).X
 
         new := NewCoord(real_x, f(real_x))
-        g.DrawLine(new, old, col)
+        g.DrawFunctionBetween(f, old, new, col)
         old = new
     }
 
@@ -602,26 +1043,34 @@ func (g *Graph) DrawFunction(f Function) {
     g.DrawFunctionWithColor(f, g.RelationColor)
 }
 
-func (g *Graph) DrawPolarFunctionInRange(f PolarFunction, start, end float64, col color.Color, ch chan struct {}) {
-    old := NewCoordFromPolar(f(start), start)
+/* Like DrawFunctionBetween, but for a sideways function */
+func (g *Graph) DrawSidewaysFunctionBetween(f SidewaysFunction, c0, c1 *Coord, col color.Color) {
+    g.drawSamplesBetween(f, c0.Transpose(), c1.Transpose(), true, 0, math.Inf(1), col)
+}
+
+func (g *Graph) DrawSidewaysFunctionInRange(f SidewaysFunction, start, end int, col color.Color, ch chan struct{}) {
+    real_start := g.PixelToCoord(image.Pt(0, start)).Y
+    old := NewCoord(f(real_start), real_start)
+
+    for y := start + 1; y <= end; y++ {
+        real_y := g.PixelToCoord(image.Pt(0, y)).Y
 
-    for theta := start + AngleStep; theta <= end + AngleStep; theta += AngleStep {
-        new := NewCoordFromPolar(f(theta), theta)
-        g.DrawLine(new, old, col)
+        new := NewCoord(f(real_y), real_y)
+        g.DrawSidewaysFunctionBetween(f, old, new, col)
         old = new
     }
 
     ch <- struct{}{}
 }
 
-func (g *Graph) DrawPolarFunctionWithColor(f PolarFunction, col color.Color) {
+func (g *Graph) DrawSidewaysFunctionWithColor(f SidewaysFunction, col color.Color) {
     var channels []chan struct{}
 
-    for theta := 0.0; theta < 2 * math.Pi; theta += AngleSize {
+    for y := 0; y < g.ImageHeight(); y += ChunkSize {
         ch := make(chan struct{})
         channels = append(channels, ch)
 
-        go g.DrawPolarFunctionInRange(f, theta, math.Min(theta + AngleSize, 2 * math.Pi), col, ch)
+        go g.DrawSidewaysFunctionInRange(f, y, MinInt(y + ChunkSize, g.ImageHeight()), col, ch)
     }
 
     for _, ch := range channels {
@@ -629,6 +1078,147 @@ func (g *Graph) DrawPolarFunctionWithColor(f PolarFunction, col color.Color) {
     }
 }
 
+func (g *Graph) DrawSidewaysFunction(f SidewaysFunction) {
+    g.DrawSidewaysFunctionWithColor(f, g.RelationColor)
+}
+
+func NewFillStyle(above, below color.Color, measure_area bool) *FillStyle {
+    return &FillStyle{above, below, measure_area}
+}
+
+/*
+    Fills between two functions for the columns of pixels
+    between left and right, which are x positions in pixels.
+    Each column is sampled at the middle of the part of it
+    that is between left and right, and columns that are only
+    partly between them are only partly filled.
+*/
+func (g *Graph) DrawFillBetweenInRange(f0, f1 Function, left, right float64, style *FillStyle, ch chan struct{}) {
+    pixel_height := g.Bounds.Height() / float64(g.ImageHeight())
+
+    for x := int(math.Floor(left)); float64(x) < right; x++ {
+        x0, x1 := math.Max(float64(x), left), math.Min(float64(x + 1), right)
+        width := x1 - x0
+
+        if width <= 0 {
+            continue
+        }
+
+        real_x := g.SubpixelToCoord((x0 + x1) / 2, 0).X
+        y0, y1 := f0(real_x), f1(real_x)
+
+        if math.IsNaN(y0) || math.IsNaN(y1) {
+            continue
+        }
+
+        col := style.Above
+        if y0 < y1 {
+            col = style.Below
+        }
+
+        if col == nil {
+            continue
+        }
+
+        /* Distances from the top of the image in pixels */
+        top := (g.Bounds.Pos0.Y - math.Min(math.Max(y0, y1), g.Bounds.Pos0.Y)) / pixel_height
+        bottom := (g.Bounds.Pos0.Y - math.Max(math.Min(y0, y1), g.Bounds.Pos1.Y)) / pixel_height
+
+        for y := int(top); float64(y) < bottom; y++ {
+            coverage := math.Min(float64(y + 1), bottom) - math.Max(float64(y), top)
+            g.SetPixelWithCoverage(image.Pt(x, y), col, coverage * width)
+        }
+    }
+
+    ch <- struct{}{}
+}
+
+/*
+    Fills the area between two functions for x values
+    between xmin and xmax. If style is nil, everything
+    between them is filled with the RelationColor.
+
+    Returns the signed area between the functions,
+    that being the integral of f0 - f1 from xmin
+    to xmax, if style.MeasureArea is set, and 0 otherwise.
+*/
+func (g *Graph) DrawFillBetween(f0, f1 Function, xmin, xmax float64, style *FillStyle) float64 {
+    if style == nil {
+        style = NewFillStyle(g.RelationColor, g.RelationColor, false)
+    }
+
+    left := math.Max(g.CoordToSubpixel(NewCoord(xmin, 0)).X, 0)
+    right := math.Min(g.CoordToSubpixel(NewCoord(xmax, 0)).X, float64(g.ImageWidth()))
+
+    var channels []chan struct{}
+
+    for x := math.Floor(left); x < right; x += ChunkSize {
+        ch := make(chan struct{})
+        channels = append(channels, ch)
+
+        go g.DrawFillBetweenInRange(f0, f1, math.Max(x, left), math.Min(x + ChunkSize, right), style, ch)
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+
+    if !style.MeasureArea {
+        return 0
+    }
+
+    return IntegrateFunction(func (x float64) float64 {
+        return f0(x) - f1(x)
+    }, xmin, xmax)
+}
+
+/* Fills the area between a function and the x axis */
+func (g *Graph) DrawAreaUnder(f Function, xmin, xmax float64, style *FillStyle) float64 {
+    return g.DrawFillBetween(f, ConstantFunction(0), xmin, xmax, style)
+}
+
+func (g *Graph) DrawPolarFunctionInRange(f PolarFunction, start, end float64, negative NegativeRadius, col color.Color, ch chan struct {}) {
+    g.DrawParametricFunctionStepping(f.ToParametric(negative), start, end, AngleStep, col)
+
+    ch <- struct{}{}
+}
+
+/*
+    Draws a polar function for theta between theta0 and theta1,
+    which may cover more than one revolution, such as for spirals.
+    Nothing is drawn if either bound isn't finite.
+*/
+func (g *Graph) DrawPolarFunctionBetweenWithColor(f PolarFunction, theta0, theta1 float64, negative NegativeRadius, col color.Color) {
+    if math.IsInf(theta0, 0) || math.IsInf(theta1, 0) || math.IsNaN(theta0) || math.IsNaN(theta1) {
+        return
+    }
+
+    if theta1 < theta0 {
+        theta0, theta1 = theta1, theta0
+    }
+
+    var channels []chan struct{}
+
+    for theta := theta0; theta < theta1; theta += AngleSize {
+        ch := make(chan struct{})
+        channels = append(channels, ch)
+
+        go g.DrawPolarFunctionInRange(f, theta, math.Min(theta + AngleSize, theta1), negative, col, ch)
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+}
+
+func (g *Graph) DrawPolarFunctionBetween(f PolarFunction, theta0, theta1 float64, negative NegativeRadius) {
+    g.DrawPolarFunctionBetweenWithColor(f, theta0, theta1, negative, g.RelationColor)
+}
+
+func (g *Graph) DrawPolarFunctionWithColor(f PolarFunction, col color.Color) {
+    g.DrawPolarFunctionBetweenWithColor(f, 0, 2 * math.Pi, ReflectNegativeRadius, col)
+}
+
 func (g *Graph) DrawPolarFunction(f PolarFunction) {
     g.DrawPolarFunctionWithColor(f, g.RelationColor)
 }
\ No newline at end of file
@@ -76,6 +76,127 @@ func InvertRelation(rel Relation) Relation {
     }
 }
 
+/*
+    Gets whether a value returned by a Relation is
+    inside the region that the Relation describes.
+    A float64 is inside wherever it is not positive,
+    so that curves like UnitCircle enclose their insides.
+*/
+func IsInside(val interface{}) bool {
+    switch val.(type) {
+        case bool:
+            return val.(bool)
+
+        case float64:
+            return val.(float64) <= 0
+    }
+
+    return false
+}
+
+/*
+    Combines the values of two Relations, using combine_float
+    if both return a float64 and combine_bool otherwise.
+    Errors are passed along as they are.
+*/
+func CombineRelations(rel0, rel1 Relation, combine_bool func (a, b bool) bool, combine_float func (a, b float64) float64) Relation {
+    return func (c *Coord) interface{} {
+        val0 := rel0(c)
+        if err, ok := val0.(error); ok {
+            return err
+        }
+
+        val1 := rel1(c)
+        if err, ok := val1.(error); ok {
+            return err
+        }
+
+        f0, ok0 := val0.(float64)
+        f1, ok1 := val1.(float64)
+        if ok0 && ok1 {
+            return combine_float(f0, f1)
+        }
+
+        return combine_bool(IsInside(val0), IsInside(val1))
+    }
+}
+
+func UnionRelation(rel0, rel1 Relation, rest ...Relation) Relation {
+    combine := func (a, b bool) bool {
+        return a || b
+    }
+
+    rel := CombineRelations(rel0, rel1, combine, math.Min)
+
+    for _, other := range rest {
+        rel = CombineRelations(rel, other, combine, math.Min)
+    }
+
+    return rel
+}
+
+func IntersectionRelation(rel0, rel1 Relation, rest ...Relation) Relation {
+    combine := func (a, b bool) bool {
+        return a && b
+    }
+
+    rel := CombineRelations(rel0, rel1, combine, math.Max)
+
+    for _, other := range rest {
+        rel = CombineRelations(rel, other, combine, math.Max)
+    }
+
+    return rel
+}
+
+/* The region inside rel0 but outside rel1 */
+func DifferenceRelation(rel0, rel1 Relation) Relation {
+    return CombineRelations(rel0, rel1, func (a, b bool) bool {
+        return a && !b
+    }, func (a, b float64) float64 {
+        return math.Max(a, -b)
+    })
+}
+
+/* The region inside exactly one of rel0 and rel1 */
+func SymmetricDifferenceRelation(rel0, rel1 Relation) Relation {
+    return CombineRelations(rel0, rel1, func (a, b bool) bool {
+        return a != b
+    }, func (a, b float64) float64 {
+        return math.Max(math.Min(a, b), -math.Max(a, b))
+    })
+}
+
+func ComplementRelation(rel Relation) Relation {
+    return func (c *Coord) interface{} {
+        switch val := rel(c); val.(type) {
+            case bool:
+                return !val.(bool)
+
+            case float64:
+                return -val.(float64)
+
+            default:
+                return val
+        }
+    }
+}
+
+/*
+    Turns a Relation into one that fills in
+    its whole inside rather than its outline.
+*/
+func InteriorRelation(rel Relation) Relation {
+    return func (c *Coord) interface{} {
+        val := rel(c)
+        if err, ok := val.(error); ok {
+            return err
+        }
+
+        return IsInside(val)
+    }
+}
+
 func OffsetFunction(f Function, off *Coord) Function {
     return func (x float64) float64 {
         return f(x - off.X) + off.Y
@@ -94,6 +215,12 @@ func ScaleFunctionPerAxis(f Function, scale_x, scale_y float64) Function {
     }
 }
 
+func ConstantFunction(val float64) Function {
+    return func (x float64) float64 {
+        return val
+    }
+}
+
 func DifferentiateFunction(f Function) Function {
     return func(x float64) float64 {
         return (f(x + DifferentiateDx) - f(x)) / DifferentiateDx
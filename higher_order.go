@@ -0,0 +1,82 @@
+package gograph
+
+import (
+    "math"
+    "image/color"
+)
+
+/*
+    Converts a HigherOrderFunction of the given order to
+    a first order ODE with x as t, whose state is y and its
+    derivatives up to one less than the order.
+*/
+func (f HigherOrderFunction) ToODE(order int) ODE {
+    return func (t float64, y []float64) []float64 {
+        dy := make([]float64, order)
+
+        copy(dy, y[1:])
+        dy[order - 1] = f(t, y)
+
+        return dy
+    }
+}
+
+/*
+    Draws the solution of a HigherOrderFunction from x0 in one
+    direction, starting with steps of dx. The values of y and its
+    derivatives at x0 are given by initial, whose length is the
+    order of the equation. The derivative of the solution is
+    drawn as well if derivative_col isn't nil.
+*/
+func (g *Graph) DrawHigherOrderFunctionInDirection(f HigherOrderFunction, x0 float64, initial []float64, dx float64, integ Integrator, col, derivative_col color.Color, ch chan struct{}) {
+    ode := f.ToODE(len(initial))
+
+    end := math.Max(g.Bounds.Pos1.X, x0)
+    if dx < 0 {
+        end = math.Min(g.Bounds.Pos0.X, x0)
+    }
+
+    g.DrawODESolution(ode, integ, x0, end, initial, math.Abs(dx), func (x float64, y []float64) *Coord {
+        return NewCoord(x, y[0])
+    }, col)
+
+    if derivative_col != nil && len(initial) > 1 {
+        g.DrawODESolution(ode, integ, x0, end, initial, math.Abs(dx), func (x float64, y []float64) *Coord {
+            return NewCoord(x, y[1])
+        }, derivative_col)
+    }
+
+    ch <- struct{}{}
+}
+
+func (g *Graph) DrawHigherOrderFunctionWithIntegrator(f HigherOrderFunction, x0 float64, initial []float64, integ Integrator, col, derivative_col color.Color) {
+    if len(initial) == 0 {
+        return
+    }
+
+    channels := [2]chan struct{} {
+        make(chan struct{}),
+        make(chan struct{}),
+    }
+
+    dx := g.Bounds.Width() / float64(g.ImageWidth())
+
+    go g.DrawHigherOrderFunctionInDirection(f, x0, initial, dx, integ, col, derivative_col, channels[0])
+    go g.DrawHigherOrderFunctionInDirection(f, x0, initial, -dx, integ, col, derivative_col, channels[1])
+
+    for _, ch := range channels {
+        <-ch
+    }
+}
+
+func (g *Graph) DrawHigherOrderFunctionWithColors(f HigherOrderFunction, x0 float64, initial []float64, col, derivative_col color.Color) {
+    g.DrawHigherOrderFunctionWithIntegrator(f, x0, initial, DefaultIntegrator, col, derivative_col)
+}
+
+func (g *Graph) DrawHigherOrderFunctionWithColor(f HigherOrderFunction, x0 float64, initial []float64, col color.Color) {
+    g.DrawHigherOrderFunctionWithColors(f, x0, initial, col, nil)
+}
+
+func (g *Graph) DrawHigherOrderFunction(f HigherOrderFunction, x0 float64, initial ...float64) {
+    g.DrawHigherOrderFunctionWithColor(f, x0, initial, g.RelationColor)
+}
\ No newline at end of file
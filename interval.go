@@ -0,0 +1,129 @@
+package gograph
+
+import (
+    "math"
+    "image/color"
+)
+
+const (
+    /* The radius in pixels of the dots drawn at the ends of intervals */
+    EndpointRadius = 4.0
+
+    /* The width in pixels of the outline of hollow endpoint dots */
+    EndpointOutline = 1.5
+)
+
+/* How the end of an Interval is marked */
+type Endpoint int
+
+const (
+    /* The end is not marked */
+    NoEndpoint Endpoint = iota
+
+    /* The end is included, and marked with a filled dot */
+    ClosedEndpoint
+
+    /* The end is not included, and marked with a hollow dot */
+    OpenEndpoint
+)
+
+/* A range of x values that a Function is drawn over */
+type Interval struct {
+    Min, Max float64
+    MinEnd, MaxEnd Endpoint
+}
+
+func NewInterval(min, max float64, min_end, max_end Endpoint) *Interval {
+    return &Interval{min, max, min_end, max_end}
+}
+
+/* An interval that includes both of its ends */
+func NewClosedInterval(min, max float64) *Interval {
+    return NewInterval(min, max, ClosedEndpoint, ClosedEndpoint)
+}
+
+/* An interval that includes neither of its ends */
+func NewOpenInterval(min, max float64) *Interval {
+    return NewInterval(min, max, OpenEndpoint, OpenEndpoint)
+}
+
+func (g *Graph) DrawFunctionInXRange(f Function, start, end float64, col color.Color, ch chan struct{}) {
+    dx := g.Bounds.Width() / float64(g.ImageWidth())
+    old := NewCoord(start, f(start))
+
+    for x := start; x < end; {
+        x = math.Min(x + dx, end)
+
+        new := NewCoord(x, f(x))
+        g.DrawFunctionBetween(f, old, new, col)
+        old = new
+    }
+
+    ch <- struct{}{}
+}
+
+/*
+    Marks the end of an interval at x, with inward
+    being the direction towards the rest of the interval.
+*/
+func (g *Graph) DrawEndpoint(f Function, x, inward float64, end Endpoint, col color.Color) {
+    if end == NoEndpoint || x < g.Bounds.Pos0.X || x > g.Bounds.Pos1.X {
+        return
+    }
+
+    /* Use the limit from inside the interval if the function is undefined at its end */
+    c := NewCoord(x, f(x))
+    if !c.IsValid() {
+        x += inward * 1e-3 * g.Bounds.Width() / float64(g.ImageWidth())
+        c = NewCoord(x, f(x))
+    }
+
+    if end == OpenEndpoint {
+        g.DrawHollowDot(c, EndpointRadius, EndpointOutline, col)
+    } else {
+        g.DrawDot(c, EndpointRadius, col)
+    }
+}
+
+/* Draws a function only over the x values in an interval */
+func (g *Graph) DrawFunctionOnIntervalWithColor(f Function, iv *Interval, col color.Color) {
+    padded := g.PaddedBounds()
+    start := math.Max(iv.Min, padded.Pos0.X)
+    end := math.Min(iv.Max, padded.Pos1.X)
+
+    chunk_width := ChunkSize * g.Bounds.Width() / float64(g.ImageWidth())
+
+    var channels []chan struct{}
+
+    for x := start; x < end; x += chunk_width {
+        ch := make(chan struct{})
+        channels = append(channels, ch)
+
+        go g.DrawFunctionInXRange(f, x, math.Min(x + chunk_width, end), col, ch)
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+
+    g.DrawEndpoint(f, iv.Min, 1, iv.MinEnd, col)
+    g.DrawEndpoint(f, iv.Max, -1, iv.MaxEnd, col)
+}
+
+func (g *Graph) DrawFunctionOnInterval(f Function, iv *Interval) {
+    g.DrawFunctionOnIntervalWithColor(f, iv, g.RelationColor)
+}
+
+/*
+    Draws a function over several intervals, such
+    as for one piece of a piecewise function
+*/
+func (g *Graph) DrawFunctionOnIntervalsWithColor(f Function, col color.Color, intervals ...*Interval) {
+    for _, iv := range intervals {
+        g.DrawFunctionOnIntervalWithColor(f, iv, col)
+    }
+}
+
+func (g *Graph) DrawFunctionOnIntervals(f Function, intervals ...*Interval) {
+    g.DrawFunctionOnIntervalsWithColor(f, g.RelationColor, intervals...)
+}
\ No newline at end of file
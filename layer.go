@@ -0,0 +1,153 @@
+package gograph
+
+import (
+    "io"
+    "math"
+    "sort"
+    "image"
+    "image/color"
+    "image/draw"
+    "image/png"
+)
+
+const (
+    /* The name of the layer that a Graph's background is drawn on */
+    BackgroundLayer = "background"
+
+    /* The name of the layer that a Graph draws on by default */
+    DefaultLayer = "default"
+)
+
+/*
+    A separate image that can be drawn on, which
+    gets composited with the other layers of a Graph
+    when saved. Layers with a higher Z are drawn over
+    layers with a lower Z.
+*/
+type Layer struct {
+    Name string
+    Image *image.RGBA
+
+    Opacity float64
+    Visible bool
+    Z int
+}
+
+type DuplicateLayerError struct{}
+
+type NoSuchLayerError struct{}
+
+type CurrentLayerError struct{}
+
+func (e DuplicateLayerError) Error() string {
+    return "Duplicate layer"
+}
+
+func (e NoSuchLayerError) Error() string {
+    return "No such layer"
+}
+
+func (e CurrentLayerError) Error() string {
+    return "Cannot remove the current layer"
+}
+
+/*
+    Adds a new transparent layer above all the other
+    layers. Drawing will not happen on the new layer
+    until it is switched to with UseLayer.
+*/
+func (g *Graph) NewLayer(name string) (*Layer, error) {
+    if g.Layer(name) != nil {
+        return nil, DuplicateLayerError{}
+    }
+
+    z := 0
+    for _, l := range g.Layers {
+        z = MaxInt(z, l.Z + 1)
+    }
+
+    l := &Layer{name, image.NewRGBA(g.Image.Bounds()), 1, true, z}
+    g.Layers = append(g.Layers, l)
+
+    return l, nil
+}
+
+func (g *Graph) Layer(name string) *Layer {
+    for _, l := range g.Layers {
+        if l.Name == name {
+            return l
+        }
+    }
+
+    return nil
+}
+
+func (g *Graph) CurrentLayer() *Layer {
+    return g.layer
+}
+
+/* Makes all further drawing happen on the named layer */
+func (g *Graph) UseLayer(name string) error {
+    l := g.Layer(name)
+    if l == nil {
+        return NoSuchLayerError{}
+    }
+
+    g.layer = l
+    g.Image = l.Image
+
+    return nil
+}
+
+/* Removes a layer. The current layer cannot be removed. */
+func (g *Graph) RemoveLayer(name string) error {
+    for i, l := range g.Layers {
+        if l.Name == name {
+            if l == g.layer {
+                return CurrentLayerError{}
+            }
+
+            g.Layers = append(g.Layers[:i], g.Layers[i + 1:]...)
+            return nil
+        }
+    }
+
+    return NoSuchLayerError{}
+}
+
+/*
+    Composites all the visible layers into one image,
+    with their opacities clamped between 0 and 1.
+*/
+func (g *Graph) Composite() *image.RGBA {
+    layers := make([]*Layer, len(g.Layers))
+    copy(layers, g.Layers)
+
+    sort.SliceStable(layers, func (i, j int) bool {
+        return layers[i].Z < layers[j].Z
+    })
+
+    img := image.NewRGBA(g.Image.Bounds())
+
+    for _, l := range layers {
+        if !l.Visible || !(l.Opacity > 0) {
+            continue
+        }
+
+        opacity := math.Min(l.Opacity, 1)
+        mask := image.NewUniform(color.Alpha16{uint16(opacity * 0xFFFF)})
+        draw.DrawMask(img, img.Bounds(), l.Image, image.Point{}, mask, image.Point{}, draw.Over)
+    }
+
+    return img
+}
+
+/* Saves a single layer by itself, with a transparent background */
+func (g *Graph) SaveLayerPNG(name string, w io.Writer) error {
+    l := g.Layer(name)
+    if l == nil {
+        return NoSuchLayerError{}
+    }
+
+    return png.Encode(w, l.Image)
+}
\ No newline at end of file
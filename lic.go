@@ -0,0 +1,305 @@
+package gograph
+
+import (
+    "math"
+    "image"
+    "image/color"
+)
+
+/*
+    How a vector field is drawn with line integral convolution.
+    Each pixel is the average of a noise texture along the
+    streamline through it, going Length pixels in each direction
+    in steps of Step pixels. The noise is made of square grains
+    Grain pixels wide.
+
+    If Colormap isn't nil, the texture is tinted by the
+    magnitude of the field, up to MaxMagnitude, or up to
+    the largest magnitude in the graph if that is 0.
+    Otherwise the texture goes between the BackgroundColor
+    and the RelationColor of the graph.
+*/
+type LICStyle struct {
+    Length, Step float64
+    Grain float64
+
+    Colormap Colormap
+    MaxMagnitude float64
+}
+
+/* A vector field sampled at every pixel, with its directions in pixels */
+type licField struct {
+    Width, Height int
+    Directions []*Coord
+    Magnitudes []float64
+}
+
+func NewLICStyle(length float64) *LICStyle {
+    return &LICStyle{length, 0.5, 1, nil, 0}
+}
+
+func newLICField(width, height int) *licField {
+    return &licField{width, height, make([]*Coord, width * height), make([]float64, width * height)}
+}
+
+/*
+    Gets the direction of the field at a position in pixels by
+    interpolating between the pixels around it, or false if the
+    position is outside the image or the field has no direction there.
+*/
+func (f *licField) At(x, y float64) (float64, float64, bool) {
+    if x < 0 || y < 0 || x > float64(f.Width - 1) || y > float64(f.Height - 1) {
+        return 0, 0, false
+    }
+
+    x0, y0 := MaxInt(MinInt(int(x), f.Width - 2), 0), MaxInt(MinInt(int(y), f.Height - 2), 0)
+    tx, ty := x - float64(x0), y - float64(y0)
+
+    var dx, dy float64
+
+    for _, corner := range [4]struct{ X, Y int; Weight float64 } {
+        {x0,     y0,     (1 - tx) * (1 - ty)},
+        {x0 + 1, y0,     tx * (1 - ty)},
+        {x0,     y0 + 1, (1 - tx) * ty},
+        {x0 + 1, y0 + 1, tx * ty},
+    } {
+        if corner.X >= f.Width || corner.Y >= f.Height {
+            continue
+        }
+
+        d := f.Directions[corner.Y * f.Width + corner.X]
+        if d == nil {
+            return 0, 0, false
+        }
+
+        dx += d.X * corner.Weight
+        dy += d.Y * corner.Weight
+    }
+
+    mag := math.Hypot(dx, dy)
+    if mag == 0 {
+        return 0, 0, false
+    }
+
+    return dx / mag, dy / mag, true
+}
+
+/* Gets the value of white noise made of square grains at a position in pixels */
+func licNoise(x, y, grain float64) float64 {
+    x, y = x / grain, y / grain
+
+    x0, y0 := math.Floor(x), math.Floor(y)
+    tx, ty := x - x0, y - y0
+
+    cell := func (i, j float64) float64 {
+        return math.Round(pixelHash(image.Pt(int(i), int(j)), 0))
+    }
+
+    top := cell(x0, y0) * (1 - tx) + cell(x0 + 1, y0) * tx
+    bottom := cell(x0, y0 + 1) * (1 - tx) + cell(x0 + 1, y0 + 1) * tx
+
+    return top * (1 - ty) + bottom * ty
+}
+
+func (g *Graph) sampleLICFieldInChunk(v VectorField, field *licField, r *image.Rectangle, ch chan struct{}) {
+    scale := NewCoord(float64(g.ImageWidth()) / g.Bounds.Width(), float64(g.ImageHeight()) / g.Bounds.Height())
+
+    for x := r.Min.X; x < r.Max.X; x++ {
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            vec := v(g.PixelToCoord(image.Pt(x, y)))
+            mag := vec.DistOrigin()
+
+            i := y * field.Width + x
+
+            if !vec.IsValid() || mag == 0 {
+                field.Magnitudes[i] = math.NaN()
+                continue
+            }
+
+            /* The y axis points down in pixels */
+            dir := NewCoord(vec.X * scale.X, -vec.Y * scale.Y)
+
+            field.Directions[i] = dir.Div(dir.DistOrigin())
+            field.Magnitudes[i] = mag
+        }
+    }
+
+    ch <- struct{}{}
+}
+
+/*
+    Averages the noise along the streamline through a pixel,
+    weighted so that the noise further away counts for less.
+*/
+func (f *licField) convolve(pt image.Point, style *LICStyle) float64 {
+    start_x, start_y := float64(pt.X), float64(pt.Y)
+
+    sum, weights := licNoise(start_x, start_y, style.Grain), 1.0
+
+    for _, h := range [2]float64{style.Step, -style.Step} {
+        x, y := start_x, start_y
+
+        for s := style.Step; s <= style.Length; s += style.Step {
+            /* Take a midpoint step along the streamline */
+            dx, dy, ok := f.At(x, y)
+            if !ok {
+                break
+            }
+
+            dx, dy, ok = f.At(x + dx * h / 2, y + dy * h / 2)
+            if !ok {
+                break
+            }
+
+            x, y = x + dx * h, y + dy * h
+
+            weight := (1 + math.Cos(math.Pi * s / style.Length)) / 2
+
+            sum += licNoise(x, y, style.Grain) * weight
+            weights += weight
+        }
+    }
+
+    return sum / weights
+}
+
+func (g *Graph) convolveLICInChunk(field *licField, style *LICStyle, values []float64, r *image.Rectangle, ch chan struct{}) {
+    for x := r.Min.X; x < r.Max.X; x++ {
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            i := y * field.Width + x
+
+            if field.Directions[i] == nil {
+                values[i] = math.NaN()
+                continue
+            }
+
+            values[i] = field.convolve(image.Pt(x, y), style)
+        }
+    }
+
+    ch <- struct{}{}
+}
+
+func (g *Graph) drawLICInChunk(field *licField, style *LICStyle, values []float64, mean, spread, max_mag float64, r *image.Rectangle, ch chan struct{}) {
+    for x := r.Min.X; x < r.Max.X; x++ {
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            i := y * field.Width + x
+
+            if math.IsNaN(values[i]) {
+                continue
+            }
+
+            t := math.Max(0, math.Min(1, 0.5 + (values[i] - mean) / spread))
+
+            var col color.Color
+            if style.Colormap != nil {
+                col = LerpColor(color.Black, style.Colormap(field.Magnitudes[i] / max_mag), t)
+            } else {
+                col = LerpColor(g.RelationColor, g.BackgroundColor, t)
+            }
+
+            g.SetPixel(image.Pt(x, y), col)
+        }
+    }
+
+    ch <- struct{}{}
+}
+
+/*
+    Draws a vector field over the whole graph using line integral
+    convolution, which smears noise along the streamlines of the field.
+    The field is sampled, the noise convolved, and the result drawn
+    in chunks across goroutines, with the contrast of the result
+    stretched to fill the range of colors. Nothing is drawn
+    if the Step or Grain of the style isn't positive, or if
+    the Length isn't finite.
+*/
+func (g *Graph) DrawLICWithStyle(v VectorField, style *LICStyle) {
+    if !(style.Step > 0) || !(style.Grain > 0) || math.IsInf(style.Length, 0) || math.IsNaN(style.Length) {
+        return
+    }
+
+    width, height := g.ImageWidth(), g.ImageHeight()
+
+    field := newLICField(width, height)
+    values := make([]float64, width * height)
+
+    var rects []image.Rectangle
+
+    for x := 0; x < width; x += ChunkSize {
+        for y := 0; y < height; y += ChunkSize {
+            rects = append(rects, image.Rect(x, y, MinInt(x + ChunkSize, width), MinInt(y + ChunkSize, height)))
+        }
+    }
+
+    var channels []chan struct{}
+
+    for i := range rects {
+        ch := make(chan struct{})
+        channels = append(channels, ch)
+
+        go g.sampleLICFieldInChunk(v, field, &rects[i], ch)
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+
+    channels = nil
+
+    for i := range rects {
+        ch := make(chan struct{})
+        channels = append(channels, ch)
+
+        go g.convolveLICInChunk(field, style, values, &rects[i], ch)
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+
+    var count, sum, sum_sq, max_mag float64
+    for i, val := range values {
+        if math.IsNaN(val) {
+            continue
+        }
+
+        count++
+        sum += val
+        sum_sq += val * val
+        max_mag = math.Max(max_mag, field.Magnitudes[i])
+    }
+
+    if count == 0 {
+        return
+    }
+
+    if style.MaxMagnitude != 0 {
+        max_mag = style.MaxMagnitude
+    }
+
+    /* Fit two standard deviations on either side of the mean */
+    mean := sum / count
+    spread := 4 * math.Sqrt(math.Max(sum_sq / count - mean * mean, 0))
+    if spread == 0 {
+        spread = 1
+    }
+
+    channels = nil
+
+    for i := range rects {
+        ch := make(chan struct{})
+        channels = append(channels, ch)
+
+        go g.drawLICInChunk(field, style, values, mean, spread, max_mag, &rects[i], ch)
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+}
+
+/* Draws a vector field with line integral convolution, smearing noise 10 pixels each way */
+func (g *Graph) DrawLIC(v VectorField) {
+    g.DrawLICWithStyle(v, NewLICStyle(10))
+}
\ No newline at end of file
@@ -0,0 +1,370 @@
+package gograph
+
+import (
+    "math"
+    "math/rand"
+    "image"
+)
+
+const (
+    /*
+        The number of samples along each side of a cell
+        that are used to see if it is on an edge of a region
+    */
+    MeasureSamples = 2
+
+    /*
+        The number of samples along each side of a cell
+        that are used to measure a cell on an edge of a region
+    */
+    MeasureEdgeSamples = 8
+)
+
+/* A measured value along with an estimate of its error */
+type Measurement struct {
+    Value, Error float64
+}
+
+type EmptyRegionError struct{}
+
+type NotFloatRelationError struct{}
+
+type InvalidResolutionError struct{}
+
+/* The sums of the measurements of some cells of a region */
+type regionSums struct {
+    Area, AreaVar float64
+    X, XVar float64
+    Y, YVar float64
+
+    /* The corners of the cells that had samples inside the region */
+    Min, Max *Coord
+
+    /* The length of the zero set within the cells */
+    Length float64
+
+    Err error
+}
+
+func (e EmptyRegionError) Error() string {
+    return "Empty region"
+}
+
+func (e NotFloatRelationError) Error() string {
+    return "Relation does not return a float64"
+}
+
+func (e InvalidResolutionError) Error() string {
+    return "Invalid resolution"
+}
+
+func (s *regionSums) Add(other *regionSums) {
+    s.Area += other.Area
+    s.AreaVar += other.AreaVar
+    s.X += other.X
+    s.XVar += other.XVar
+    s.Y += other.Y
+    s.YVar += other.YVar
+    s.Length += other.Length
+
+    if other.Min != nil {
+        if s.Min == nil {
+            s.Min, s.Max = other.Min, other.Max
+        } else {
+            s.Min = NewCoord(math.Min(s.Min.X, other.Min.X), math.Min(s.Min.Y, other.Min.Y))
+            s.Max = NewCoord(math.Max(s.Max.X, other.Max.X), math.Max(s.Max.Y, other.Max.Y))
+        }
+    }
+
+    if s.Err == nil {
+        s.Err = other.Err
+    }
+}
+
+/* Gets the top left corner of a cell */
+func cellCorner(bounds *Area, cell_size *Coord, x, y int) *Coord {
+    return NewCoord(bounds.Pos0.X + float64(x) * cell_size.X, bounds.Pos0.Y - float64(y) * cell_size.Y)
+}
+
+/*
+    Takes stratified random samples over a cell, with n samples
+    along each side, and returns the sums of the measurements
+    of the samples that were inside the region.
+*/
+func sampleCell(rel Relation, corner, cell_size *Coord, n int, rnd *rand.Rand) (count, sx, sxx, sy, syy float64, err error) {
+    for i := 0; i < n; i++ {
+        for j := 0; j < n; j++ {
+            c := NewCoord(
+                corner.X + (float64(i) + rnd.Float64()) * cell_size.X / float64(n),
+                corner.Y - (float64(j) + rnd.Float64()) * cell_size.Y / float64(n),
+            )
+
+            val := rel(c)
+            if e, ok := val.(error); ok {
+                err = e
+                return
+            }
+
+            if IsInside(val) {
+                count++
+                sx += c.X
+                sxx += c.X * c.X
+                sy += c.Y
+                syy += c.Y * c.Y
+            }
+        }
+    }
+
+    return
+}
+
+func measureRegionInChunk(rel Relation, bounds *Area, cell_size *Coord, r *image.Rectangle, ch chan *regionSums) {
+    sums := &regionSums{}
+    rnd := rand.New(rand.NewSource(int64(r.Min.X) << 32 | int64(r.Min.Y)))
+    cell_area := cell_size.X * cell_size.Y
+
+    for x := r.Min.X; x < r.Max.X; x++ {
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            corner := cellCorner(bounds, cell_size, x, y)
+
+            n := MeasureSamples
+            count, sx, sxx, sy, syy, err := sampleCell(rel, corner, cell_size, n, rnd)
+
+            /* Only cells on an edge need more samples */
+            if err == nil && count != 0 && count != float64(n * n) {
+                n = MeasureEdgeSamples
+                count, sx, sxx, sy, syy, err = sampleCell(rel, corner, cell_size, n, rnd)
+            }
+
+            if err != nil {
+                sums.Err = err
+                ch <- sums
+                return
+            }
+
+            if count == 0 {
+                continue
+            }
+
+            samples := float64(n * n)
+            p := count / samples
+            mean_x, mean_y := sx / samples, sy / samples
+
+            sums.Area += cell_area * p
+            sums.X += cell_area * mean_x
+            sums.Y += cell_area * mean_y
+
+            /* Cells that were entirely inside contribute no variance */
+            if count != samples {
+                sums.AreaVar += cell_area * cell_area * p * (1 - p) / samples
+                sums.XVar += cell_area * cell_area * (sxx / samples - mean_x * mean_x) / samples
+                sums.YVar += cell_area * cell_area * (syy / samples - mean_y * mean_y) / samples
+            }
+
+            sums.Add(&regionSums{Min: NewCoord(corner.X, corner.Y - cell_size.Y), Max: NewCoord(corner.X + cell_size.X, corner.Y)})
+        }
+    }
+
+    ch <- sums
+}
+
+/*
+    Measures the region inside a Relation within the bounds,
+    splitting the bounds into a grid of resolution by resolution
+    cells which are then sampled in chunks across goroutines.
+*/
+func measureRegion(rel Relation, bounds *Area, resolution int) (*regionSums, error) {
+    if resolution <= 0 {
+        return nil, InvalidResolutionError{}
+    }
+
+    cell_size := NewCoord(bounds.Width() / float64(resolution), bounds.Height() / float64(resolution))
+
+    var channels []chan *regionSums
+
+    for x := 0; x < resolution; x += ChunkSize {
+        for y := 0; y < resolution; y += ChunkSize {
+            ch := make(chan *regionSums)
+            channels = append(channels, ch)
+
+            r := image.Rect(x, y, MinInt(x + ChunkSize, resolution), MinInt(y + ChunkSize, resolution))
+            go measureRegionInChunk(rel, bounds, cell_size, &r, ch)
+        }
+    }
+
+    sums := &regionSums{}
+    for _, ch := range channels {
+        sums.Add(<-ch)
+    }
+
+    return sums, sums.Err
+}
+
+/*
+    Measures the area inside a Relation within the bounds.
+    A float64 Relation is treated as inside where it is not
+    positive, the same as IsInside.
+*/
+func MeasureArea(rel Relation, bounds *Area, resolution int) (*Measurement, error) {
+    sums, err := measureRegion(rel, bounds, resolution)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Measurement{sums.Area, math.Sqrt(sums.AreaVar)}, nil
+}
+
+func MeasureCentroid(rel Relation, bounds *Area, resolution int) (x, y *Measurement, err error) {
+    sums, err := measureRegion(rel, bounds, resolution)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    if sums.Area == 0 {
+        return nil, nil, EmptyRegionError{}
+    }
+
+    cx, cy := sums.X / sums.Area, sums.Y / sums.Area
+
+    x = &Measurement{cx, math.Sqrt(sums.XVar + cx * cx * sums.AreaVar) / sums.Area}
+    y = &Measurement{cy, math.Sqrt(sums.YVar + cy * cy * sums.AreaVar) / sums.Area}
+
+    return
+}
+
+/*
+    Measures the smallest Area containing the region
+    inside a Relation, to within the size of one cell.
+*/
+func MeasureBoundingBox(rel Relation, bounds *Area, resolution int) (*Area, error) {
+    sums, err := measureRegion(rel, bounds, resolution)
+    if err != nil {
+        return nil, err
+    }
+
+    if sums.Min == nil {
+        return nil, EmptyRegionError{}
+    }
+
+    return NewArea(sums.Min.X, sums.Max.Y, sums.Max.X, sums.Min.Y)
+}
+
+/* Finds where the zero set of a Relation crosses between two corners */
+func zeroCrossing(c0, c1 *Coord, v0, v1 float64) *Coord {
+    return c0.Add(c1.Sub(c0).Mult(v0 / (v0 - v1)))
+}
+
+func measurePerimeterInChunk(rel Relation, bounds *Area, cell_size *Coord, r *image.Rectangle, ch chan *regionSums) {
+    sums := &regionSums{}
+
+cells:
+    for x := r.Min.X; x < r.Max.X; x++ {
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            /* Clockwise from the top left */
+            corners := [4]*Coord {
+                cellCorner(bounds, cell_size, x, y),
+                cellCorner(bounds, cell_size, x + 1, y),
+                cellCorner(bounds, cell_size, x + 1, y + 1),
+                cellCorner(bounds, cell_size, x, y + 1),
+            }
+
+            var vals [4]float64
+            for i, c := range corners {
+                switch val := rel(c); val.(type) {
+                    case float64:
+                        if math.IsNaN(val.(float64)) || math.IsInf(val.(float64), 0) {
+                            continue cells
+                        }
+
+                        vals[i] = val.(float64)
+
+                    case error:
+                        sums.Err = val.(error)
+                        ch <- sums
+                        return
+
+                    default:
+                        sums.Err = NotFloatRelationError{}
+                        ch <- sums
+                        return
+                }
+            }
+
+            var crossings []*Coord
+            for i := range corners {
+                j := (i + 1) % 4
+
+                if (vals[i] > 0) != (vals[j] > 0) {
+                    crossings = append(crossings, zeroCrossing(corners[i], corners[j], vals[i], vals[j]))
+                }
+            }
+
+            switch len(crossings) {
+                case 2:
+                    sums.Length += crossings[0].Dist(crossings[1])
+
+                case 4:
+                    /*
+                        The corners alternate in sign, so use the center
+                        to decide which pair of opposite corners is joined
+                        and cut off the other two corners.
+                    */
+                    center := corners[0].Add(corners[2]).Div(2)
+                    if center_val, ok := rel(center).(float64); ok && (center_val > 0) == (vals[0] > 0) {
+                        sums.Length += crossings[0].Dist(crossings[1]) + crossings[2].Dist(crossings[3])
+                    } else {
+                        sums.Length += crossings[3].Dist(crossings[0]) + crossings[1].Dist(crossings[2])
+                    }
+            }
+        }
+    }
+
+    ch <- sums
+}
+
+func measurePerimeter(rel Relation, bounds *Area, resolution int) (float64, error) {
+    if resolution <= 0 {
+        return 0, InvalidResolutionError{}
+    }
+
+    cell_size := NewCoord(bounds.Width() / float64(resolution), bounds.Height() / float64(resolution))
+
+    var channels []chan *regionSums
+
+    for x := 0; x < resolution; x += ChunkSize {
+        for y := 0; y < resolution; y += ChunkSize {
+            ch := make(chan *regionSums)
+            channels = append(channels, ch)
+
+            r := image.Rect(x, y, MinInt(x + ChunkSize, resolution), MinInt(y + ChunkSize, resolution))
+            go measurePerimeterInChunk(rel, bounds, cell_size, &r, ch)
+        }
+    }
+
+    sums := &regionSums{}
+    for _, ch := range channels {
+        sums.Add(<-ch)
+    }
+
+    return sums.Length, sums.Err
+}
+
+/*
+    Measures the length of the zero set of a Relation that
+    returns a float64 within the bounds, by tracing it through
+    a grid of resolution by resolution cells. The error is
+    estimated by comparing against a grid of half the resolution.
+*/
+func MeasurePerimeter(rel Relation, bounds *Area, resolution int) (*Measurement, error) {
+    fine, err := measurePerimeter(rel, bounds, resolution)
+    if err != nil {
+        return nil, err
+    }
+
+    coarse, err := measurePerimeter(rel, bounds, MaxInt(resolution / 2, 1))
+    if err != nil {
+        return nil, err
+    }
+
+    return &Measurement{fine, math.Abs(fine - coarse) / 3}, nil
+}
\ No newline at end of file
@@ -0,0 +1,436 @@
+package gograph
+
+import (
+    "math"
+    "image/color"
+)
+
+/*
+    A system of first order differential equations
+    dy/dt = f(t, y), where y is a vector of values.
+    Returning nil means the derivative is undefined.
+*/
+type ODE func (t float64, y []float64) []float64
+
+/*
+    A method for numerically solving an ODE.
+
+    Step tries to take a step of size h from t and y,
+    and returns the new state along with the size of the
+    step it actually took and the size to try next time.
+    Adaptive integrators may take a smaller step than asked.
+    A nil state means that the step couldn't be taken.
+*/
+type Integrator interface {
+    Step(f ODE, t float64, y []float64, h float64) (new_y []float64, taken, next float64)
+}
+
+/* The forward Euler method, which is simple but inaccurate */
+type EulerIntegrator struct{}
+
+/* The classic fourth order Runge-Kutta method */
+type RK4Integrator struct{}
+
+/*
+    The Dormand-Prince method, which takes steps with a fifth
+    order Runge-Kutta method and changes their size to keep the
+    error estimated from an embedded fourth order one within
+    AbsTolerance plus RelTolerance times the size of the state.
+    Steps smaller than MinStep are never taken.
+*/
+type RK45Integrator struct {
+    RelTolerance, AbsTolerance float64
+    MinStep float64
+}
+
+/*
+    The backward Euler method, which is implicit and
+    so stays stable on stiff equations where explicit
+    methods would blow up unless their steps were tiny.
+    Each step is solved with Newton's method, stopping
+    once an iteration changes the state by less than
+    Tolerance or after MaxIterations iterations.
+*/
+type BackwardEulerIntegrator struct {
+    Tolerance float64
+    MaxIterations int
+}
+
+/* The integrator used when one isn't given */
+var DefaultIntegrator Integrator = RK4Integrator{}
+
+func NewRK45Integrator(rel_tolerance, abs_tolerance float64) *RK45Integrator {
+    return &RK45Integrator{rel_tolerance, abs_tolerance, 1e-12}
+}
+
+func NewBackwardEulerIntegrator() *BackwardEulerIntegrator {
+    return &BackwardEulerIntegrator{1e-10, 20}
+}
+
+/* Converts a DifferentialFunction to an ODE with x as t */
+func (d DifferentialFunction) ToODE() ODE {
+    return func (t float64, y []float64) []float64 {
+        return []float64{d(NewCoord(t, y[0]))}
+    }
+}
+
+/* Whether a state has all of its values defined and finite */
+func validState(y []float64) bool {
+    if y == nil {
+        return false
+    }
+
+    for _, val := range y {
+        if math.IsNaN(val) || math.IsInf(val, 0) {
+            return false
+        }
+    }
+
+    return true
+}
+
+/* Gets y plus h times the sum of the coefficients times the derivatives */
+func combineStates(y []float64, h float64, coeffs []float64, ks [][]float64) []float64 {
+    new_y := make([]float64, len(y))
+    copy(new_y, y)
+
+    for i, coeff := range coeffs {
+        if coeff == 0 {
+            continue
+        }
+
+        for j := range new_y {
+            new_y[j] += h * coeff * ks[i][j]
+        }
+    }
+
+    return new_y
+}
+
+/* Evaluates an ODE, returning nil if the result isn't valid */
+func (f ODE) eval(t float64, y []float64) []float64 {
+    dy := f(t, y)
+    if !validState(dy) || len(dy) != len(y) {
+        return nil
+    }
+
+    return dy
+}
+
+func (i EulerIntegrator) Step(f ODE, t float64, y []float64, h float64) ([]float64, float64, float64) {
+    k := f.eval(t, y)
+    if k == nil {
+        return nil, h, h
+    }
+
+    return combineStates(y, h, []float64{1}, [][]float64{k}), h, h
+}
+
+func (i RK4Integrator) Step(f ODE, t float64, y []float64, h float64) ([]float64, float64, float64) {
+    var ks [][]float64
+
+    for _, stage := range [4]struct{ C float64; Coeffs []float64 } {
+        {0,   nil},
+        {0.5, []float64{0.5}},
+        {0.5, []float64{0, 0.5}},
+        {1,   []float64{0, 0, 1}},
+    } {
+        k := f.eval(t + stage.C * h, combineStates(y, h, stage.Coeffs, ks))
+        if k == nil {
+            return nil, h, h
+        }
+
+        ks = append(ks, k)
+    }
+
+    return combineStates(y, h, []float64{1.0 / 6, 1.0 / 3, 1.0 / 3, 1.0 / 6}, ks), h, h
+}
+
+/* The Butcher tableau of the Dormand-Prince method */
+var (
+    dormandPrinceC = [7]float64{0, 1.0 / 5, 3.0 / 10, 4.0 / 5, 8.0 / 9, 1, 1}
+
+    dormandPrinceA = [7][]float64 {
+        nil,
+        {1.0 / 5},
+        {3.0 / 40, 9.0 / 40},
+        {44.0 / 45, -56.0 / 15, 32.0 / 9},
+        {19372.0 / 6561, -25360.0 / 2187, 64448.0 / 6561, -212.0 / 729},
+        {9017.0 / 3168, -355.0 / 33, 46732.0 / 5247, 49.0 / 176, -5103.0 / 18656},
+        {35.0 / 384, 0, 500.0 / 1113, 125.0 / 192, -2187.0 / 6784, 11.0 / 84},
+    }
+
+    /* The difference between the fifth and fourth order weights */
+    dormandPrinceE = []float64 {
+        71.0 / 57600, 0, -71.0 / 16695, 71.0 / 1920, -17253.0 / 339200, 22.0 / 525, -1.0 / 40,
+    }
+)
+
+func (i *RK45Integrator) Step(f ODE, t float64, y []float64, h float64) ([]float64, float64, float64) {
+    for math.Abs(h) >= i.MinStep {
+        var ks [][]float64
+
+        for stage := range dormandPrinceC {
+            k := f.eval(t + dormandPrinceC[stage] * h, combineStates(y, h, dormandPrinceA[stage], ks))
+            if k == nil {
+                break
+            }
+
+            ks = append(ks, k)
+        }
+
+        /* Undefined somewhere within the step, so try a smaller one */
+        if len(ks) != len(dormandPrinceC) {
+            h /= 2
+            continue
+        }
+
+        /* The last stage is evaluated at the new state */
+        new_y := combineStates(y, h, dormandPrinceA[6], ks)
+        err_y := combineStates(make([]float64, len(y)), h, dormandPrinceE, ks)
+
+        err := 0.0
+        for j := range y {
+            scale := i.AbsTolerance + i.RelTolerance * math.Max(math.Abs(y[j]), math.Abs(new_y[j]))
+            err += (err_y[j] / scale) * (err_y[j] / scale)
+        }
+
+        err = math.Sqrt(err / float64(len(y)))
+
+        /* Keep the step size from changing too wildly */
+        factor := 5.0
+        if err != 0 {
+            factor = math.Max(0.2, math.Min(5, 0.9 * math.Pow(err, -0.2)))
+        }
+
+        if err <= 1 {
+            return new_y, h, h * factor
+        }
+
+        h *= factor
+    }
+
+    return nil, h, h
+}
+
+/*
+    Solves a system of linear equations with Gaussian elimination,
+    returning nil if the system doesn't have a single solution.
+    The matrix and vector given are changed.
+*/
+func solveLinear(a [][]float64, b []float64) []float64 {
+    n := len(b)
+
+    for col := 0; col < n; col++ {
+        pivot := col
+        for row := col + 1; row < n; row++ {
+            if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+                pivot = row
+            }
+        }
+
+        if a[pivot][col] == 0 {
+            return nil
+        }
+
+        a[col], a[pivot] = a[pivot], a[col]
+        b[col], b[pivot] = b[pivot], b[col]
+
+        for row := col + 1; row < n; row++ {
+            factor := a[row][col] / a[col][col]
+
+            for k := col; k < n; k++ {
+                a[row][k] -= factor * a[col][k]
+            }
+
+            b[row] -= factor * b[col]
+        }
+    }
+
+    x := make([]float64, n)
+    for row := n - 1; row >= 0; row-- {
+        sum := b[row]
+        for k := row + 1; k < n; k++ {
+            sum -= a[row][k] * x[k]
+        }
+
+        x[row] = sum / a[row][row]
+    }
+
+    return x
+}
+
+/*
+    Approximates the Jacobian of an ODE at a state with finite
+    differences, returning nil if it is undefined nearby.
+*/
+func (f ODE) jacobian(t float64, y, dy []float64) [][]float64 {
+    n := len(y)
+    jac := make([][]float64, n)
+    for row := range jac {
+        jac[row] = make([]float64, n)
+    }
+
+    shifted := make([]float64, n)
+    copy(shifted, y)
+
+    for col := 0; col < n; col++ {
+        delta := math.Sqrt(2.2e-16) * math.Max(1, math.Abs(y[col]))
+
+        shifted[col] = y[col] + delta
+        shifted_dy := f.eval(t, shifted)
+        shifted[col] = y[col]
+
+        if shifted_dy == nil {
+            return nil
+        }
+
+        for row := 0; row < n; row++ {
+            jac[row][col] = (shifted_dy[row] - dy[row]) / delta
+        }
+    }
+
+    return jac
+}
+
+func (i *BackwardEulerIntegrator) Step(f ODE, t float64, y []float64, h float64) ([]float64, float64, float64) {
+    n := len(y)
+
+    /* Start from a forward Euler step */
+    k := f.eval(t, y)
+    if k == nil {
+        return nil, h, h
+    }
+
+    new_t := t + h
+    new_y := combineStates(y, h, []float64{1}, [][]float64{k})
+
+    for iter := 0; iter < i.MaxIterations; iter++ {
+        dy := f.eval(new_t, new_y)
+        if dy == nil {
+            return nil, h, h
+        }
+
+        jac := f.jacobian(new_t, new_y, dy)
+        if jac == nil {
+            return nil, h, h
+        }
+
+        /* Solve (I - h J) delta = -(new_y - y - h f(new_t, new_y)) */
+        residual := make([]float64, n)
+        for row := 0; row < n; row++ {
+            residual[row] = -(new_y[row] - y[row] - h * dy[row])
+
+            for col := 0; col < n; col++ {
+                jac[row][col] *= -h
+            }
+
+            jac[row][row] += 1
+        }
+
+        delta := solveLinear(jac, residual)
+        if delta == nil {
+            return nil, h, h
+        }
+
+        size := 0.0
+        for row := range new_y {
+            new_y[row] += delta[row]
+            size = math.Max(size, math.Abs(delta[row]) / math.Max(1, math.Abs(new_y[row])))
+        }
+
+        if size < i.Tolerance {
+            return new_y, h, h
+        }
+    }
+
+    /* Newton's method didn't converge, so the step was too big */
+    return nil, h, h
+}
+
+/*
+    Draws the curve traced out by solving an ODE from t0 towards t1,
+    with point giving where each state is drawn. The first step is
+    h long, and like when drawing parametric functions, steps are
+    halved while they move more than MaxParametricStep pixels and
+    doubled while they move less than MinParametricStep, as long as
+    the integrator doesn't want them to be smaller. The curve stops
+    once it reaches t1 or where it can't continue, such as where the
+    ODE is undefined or at a singularity, where the curve jumps.
+    Curves that leave the graph keep going, since they may come back.
+*/
+func (g *Graph) DrawODESolution(f ODE, integ Integrator, t0, t1 float64, y []float64, h float64, point func (t float64, y []float64) *Coord, col color.Color) {
+    if !validState(y) {
+        return
+    }
+
+    t := t0
+
+    old := point(t, y)
+    if !old.IsValid() {
+        return
+    }
+
+    h = math.Copysign(h, t1 - t0)
+
+    padded := g.PaddedBounds()
+
+    min_h, max_h := math.Abs(h) / (1 << MaxFunctionDepth), 2 * math.Abs(h)
+    max_steps := (g.ImageWidth() + g.ImageHeight()) << MaxFunctionDepth
+
+    for steps := 0; steps < max_steps && t != t1; steps++ {
+        /* Don't step past the end */
+        if math.Abs(h) > math.Abs(t1 - t) {
+            h = t1 - t
+        }
+
+        new_y, taken, next := integ.Step(f, t, y, h)
+
+        var c *Coord
+        if validState(new_y) && len(new_y) == len(y) {
+            c = point(t + taken, new_y)
+        }
+
+        if c == nil || !c.IsValid() {
+            if math.Abs(h) / 2 < min_h {
+                return
+            }
+
+            h /= 2
+            continue
+        }
+
+        /* Steps that stay off to one side of the graph can't be seen, so they aren't checked for jumps */
+        if !padded.OffSameSide(old, c) {
+            dist := g.CoordToSubpixel(c).Dist(g.CoordToSubpixel(old))
+
+            if dist > MaxParametricStep {
+                /* Anything still too far apart at the smallest step is a jump */
+                if math.Abs(taken) / 2 < min_h {
+                    return
+                }
+
+                h = taken / 2
+                continue
+            }
+
+            g.DrawLine(old, c, col)
+
+            /* Integrators that don't change the step size leave it to how far the curve moves */
+            if dist < MinParametricStep && next == taken {
+                next = 2 * taken
+            }
+        } else if next == taken {
+            next = 2 * taken
+        }
+
+        t, y, old = t + taken, new_y, c
+
+        /* Land exactly on the end instead of just short of it */
+        if math.Abs(t1 - t) < min_h {
+            t = t1
+        }
+
+        h = math.Copysign(math.Max(math.Min(math.Abs(next), max_h), min_h), h)
+    }
+}
\ No newline at end of file
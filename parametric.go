@@ -0,0 +1,114 @@
+package gograph
+
+import (
+    "math"
+    "image/color"
+)
+
+type OpenCurveError struct{}
+
+func (e OpenCurveError) Error() string {
+    return "Curve is not closed"
+}
+
+/*
+    Converts a parametric function to a Relation that
+    returns the signed distance to the curve traced out
+    between t0 and t1, which is only possible if the curve
+    ends where it starts. Curves that cross themselves
+    will alternate between inside and outside.
+*/
+func (f ParametricFunction) ToRelation(t0, t1 float64) (Relation, error) {
+    points := make([]*Coord, ParametricSamples)
+
+    for i := range points {
+        points[i] = f(t0 + (t1 - t0) * float64(i) / ParametricSamples)
+    }
+
+    if !f(t1).WithinDist(points[0], 1e-9 * math.Max(1, points[0].DistOrigin())) {
+        return nil, OpenCurveError{}
+    }
+
+    return polygon(points), nil
+}
+
+/* Whether the line between two coordinates is entirely off one side of the bounds */
+func (a *Area) OffSameSide(c0, c1 *Coord) bool {
+    return (c0.X < a.Pos0.X && c1.X < a.Pos0.X) || (c0.X > a.Pos1.X && c1.X > a.Pos1.X) ||
+           (c0.Y > a.Pos0.Y && c1.Y > a.Pos0.Y) || (c0.Y < a.Pos1.Y && c1.Y < a.Pos1.Y)
+}
+
+/*
+    Draws a parametric function between two t values, starting
+    with steps of dt and then adjusting the step size so that each
+    step is around a pixel long. Nothing is drawn where the function
+    is undefined or where it jumps.
+*/
+func (g *Graph) DrawParametricFunctionStepping(f ParametricFunction, start, end, dt float64, col color.Color) {
+    padded := g.PaddedBounds()
+    min_dt, max_dt := dt / (1 << MaxFunctionDepth), dt
+
+    old := f(start)
+
+    for t := start; t < end; {
+        next_t := math.Min(t + dt, end)
+        new := f(next_t)
+
+        valid_old, valid_new := old.IsValid(), new.IsValid()
+
+        /* Narrow in on where the function becomes defined or undefined */
+        if valid_old != valid_new && dt > min_dt {
+            dt /= 2
+            continue
+        }
+
+        if valid_old && valid_new && !padded.OffSameSide(old, new) {
+            dist := g.CoordToSubpixel(new).Dist(g.CoordToSubpixel(old))
+
+            if dist > MaxParametricStep && dt > min_dt {
+                dt /= 2
+                continue
+            }
+
+            /* Anything still too far apart at the smallest step is a jump */
+            if dist <= MaxParametricStep {
+                g.DrawLine(old, new, col)
+            }
+
+            if dist < MinParametricStep {
+                dt = math.Min(2 * dt, max_dt)
+            }
+        } else {
+            dt = math.Min(2 * dt, max_dt)
+        }
+
+        t, old = next_t, new
+    }
+}
+
+func (g *Graph) DrawParametricFunctionInRange(f ParametricFunction, start, end float64, col color.Color, ch chan struct{}) {
+    g.DrawParametricFunctionStepping(f, start, end, (end - start) * ParametricChunks / ParametricSamples, col)
+
+    ch <- struct{}{}
+}
+
+func (g *Graph) DrawParametricFunctionWithColor(f ParametricFunction, t0, t1 float64, col color.Color) {
+    var channels []chan struct{}
+
+    chunk := (t1 - t0) / ParametricChunks
+
+    for i := 0; i < ParametricChunks; i++ {
+        ch := make(chan struct{})
+        channels = append(channels, ch)
+
+        go g.DrawParametricFunctionInRange(f, t0 + chunk * float64(i), t0 + chunk * float64(i + 1), col, ch)
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+}
+
+func (g *Graph) DrawParametricFunction(f ParametricFunction, t0, t1 float64) {
+    g.DrawParametricFunctionWithColor(f, t0, t1, g.RelationColor)
+}
\ No newline at end of file
@@ -0,0 +1,207 @@
+package gograph
+
+import (
+    "math"
+    "image"
+    "image/color"
+)
+
+/* The space that a pattern is anchored in */
+type PatternSpace int
+
+const (
+    /*
+        The pattern is anchored to the image, and
+        its lengths are measured in pixels.
+    */
+    PixelSpace PatternSpace = iota
+
+    /*
+        The pattern is anchored to the graph, and
+        its lengths are measured in graph units,
+        so it moves and scales along with the Bounds.
+    */
+    GraphSpace
+)
+
+/*
+    A fill whose color depends on where it is drawn.
+
+    A Pattern is also a color.Color, reporting its
+    foreground color, so it can be passed anywhere
+    a color is accepted when drawing on a Graph.
+    SetPixel will then ask the pattern for the
+    color of each pixel it fills.
+*/
+type Pattern interface {
+    color.Color
+
+    ColorAt(g *Graph, pt image.Point) color.Color
+}
+
+/* Parallel lines drawn at an angle */
+type HatchPattern struct {
+    Color, Background color.Color
+
+    /* The angle of the lines, counterclockwise from the x axis */
+    Angle float64
+
+    Spacing, Width float64
+    Space PatternSpace
+}
+
+/* Two sets of perpendicular parallel lines */
+type CrossHatchPattern struct {
+    HatchPattern
+}
+
+/* Dots placed on a square grid */
+type DotPattern struct {
+    Color, Background color.Color
+
+    Spacing, Radius float64
+    Space PatternSpace
+}
+
+/* An image repeated over and over */
+type ImagePattern struct {
+    Tile image.Image
+
+    /* The size of one pixel of the tile */
+    Size float64
+    Space PatternSpace
+}
+
+type InvalidPatternError struct{}
+
+func (e InvalidPatternError) Error() string {
+    return "Invalid pattern"
+}
+
+func NewHatchPattern(col color.Color, angle, spacing, width float64, space PatternSpace) *HatchPattern {
+    return &HatchPattern{col, nil, angle, spacing, width, space}
+}
+
+func NewDiagonalHatchPattern(col color.Color, spacing, width float64, space PatternSpace) *HatchPattern {
+    return NewHatchPattern(col, math.Pi / 4, spacing, width, space)
+}
+
+func NewCrossHatchPattern(col color.Color, angle, spacing, width float64, space PatternSpace) *CrossHatchPattern {
+    return &CrossHatchPattern{*NewHatchPattern(col, angle, spacing, width, space)}
+}
+
+func NewDotPattern(col color.Color, spacing, radius float64, space PatternSpace) *DotPattern {
+    return &DotPattern{col, nil, spacing, radius, space}
+}
+
+func NewImagePattern(tile image.Image, size float64, space PatternSpace) (*ImagePattern, error) {
+    if tile.Bounds().Empty() || !(size > 0) || math.IsInf(size, 1) {
+        return nil, InvalidPatternError{}
+    }
+
+    return &ImagePattern{tile, size, space}, nil
+}
+
+/*
+    Returns the center of a pixel in the given space
+    with the y axis pointing up, along with the amount
+    of pixels in one unit of that space.
+*/
+func patternPoint(g *Graph, pt image.Point, space PatternSpace) (*Coord, float64) {
+    if space == GraphSpace {
+        c := g.PixelToCoord(pt).Add(NewCoord(0.5, -0.5).Mult(g.Bounds.Width() / float64(g.ImageWidth())))
+
+        return c, float64(g.ImageWidth()) / g.Bounds.Width()
+    }
+
+    return NewCoord(float64(pt.X) + 0.5, -(float64(pt.Y) + 0.5)), 1
+}
+
+/* Gets the distance from a value to the nearest multiple of a spacing */
+func distToMultiple(val, spacing float64) float64 {
+    return math.Abs(val - spacing * math.Round(val / spacing))
+}
+
+/*
+    Gets how much of a pixel is covered by a shape of
+    the given half-width whose center is dist pixels away.
+*/
+func edgeCoverage(half_width, dist float64) float64 {
+    return math.Max(0, math.Min(1, half_width + 0.5 - dist))
+}
+
+/* Blends the foreground of a pattern over its background, in linear light if the graph uses it */
+func patternColor(g *Graph, fg, bg color.Color, coverage float64) color.Color {
+    fg = ScaleAlpha(fg, coverage)
+
+    if bg == nil {
+        return fg
+    }
+
+    if g.LinearLight {
+        return BlendColorLinear(bg, fg, BlendNormal)
+    }
+
+    return BlendColor(bg, fg)
+}
+
+func (p *HatchPattern) RGBA() (r, g, b, a uint32) {
+    return p.Color.RGBA()
+}
+
+func (p *HatchPattern) coverage(c *Coord, scale, angle float64) float64 {
+    dist := distToMultiple(c.Y * math.Cos(angle) - c.X * math.Sin(angle), p.Spacing)
+
+    return edgeCoverage(p.Width * scale / 2, dist * scale)
+}
+
+func (p *HatchPattern) ColorAt(g *Graph, pt image.Point) color.Color {
+    c, scale := patternPoint(g, pt, p.Space)
+
+    return patternColor(g, p.Color, p.Background, p.coverage(c, scale, p.Angle))
+}
+
+func (p *CrossHatchPattern) ColorAt(g *Graph, pt image.Point) color.Color {
+    c, scale := patternPoint(g, pt, p.Space)
+
+    coverage := math.Max(p.coverage(c, scale, p.Angle), p.coverage(c, scale, p.Angle + math.Pi / 2))
+
+    return patternColor(g, p.Color, p.Background, coverage)
+}
+
+func (p *DotPattern) RGBA() (r, g, b, a uint32) {
+    return p.Color.RGBA()
+}
+
+func (p *DotPattern) ColorAt(g *Graph, pt image.Point) color.Color {
+    c, scale := patternPoint(g, pt, p.Space)
+
+    dist := math.Hypot(distToMultiple(c.X, p.Spacing), distToMultiple(c.Y, p.Spacing))
+
+    return patternColor(g, p.Color, p.Background, edgeCoverage(p.Radius * scale, dist * scale))
+}
+
+func (p *ImagePattern) RGBA() (r, g, b, a uint32) {
+    return p.Tile.At(p.Tile.Bounds().Min.X, p.Tile.Bounds().Min.Y).RGBA()
+}
+
+func (p *ImagePattern) ColorAt(g *Graph, pt image.Point) color.Color {
+    c, _ := patternPoint(g, pt, p.Space)
+
+    bounds := p.Tile.Bounds()
+    if bounds.Empty() || !(p.Size > 0) || math.IsInf(p.Size, 1) {
+        return color.Transparent
+    }
+    x := int(math.Floor(c.X / p.Size)) % bounds.Dx()
+    y := int(math.Floor(-c.Y / p.Size)) % bounds.Dy()
+
+    if x < 0 {
+        x += bounds.Dx()
+    }
+
+    if y < 0 {
+        y += bounds.Dy()
+    }
+
+    return p.Tile.At(bounds.Min.X + x, bounds.Min.Y + y)
+}
\ No newline at end of file
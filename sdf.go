@@ -0,0 +1,240 @@
+package gograph
+
+import "math"
+
+/*
+    Relations that return the exact signed distance
+    to the edge of a shape, negative inside and positive
+    outside. Because the distance is exact, they can be
+    combined with UnionRelation, IntersectionRelation and
+    the other combinators, as well as OffsetRelation and
+    RotateRelation, and still describe distances. Scaling
+    needs ScaleDistanceRelation to keep the distance exact.
+*/
+
+/*
+    Changes the values of a Relation that returns a float64,
+    leaving any other values as they are.
+*/
+func MapRelation(rel Relation, f func (val float64) float64) Relation {
+    return func (c *Coord) interface{} {
+        val := rel(c)
+        if d, ok := val.(float64); ok {
+            return f(d)
+        }
+
+        return val
+    }
+}
+
+/* Scales a distance Relation so that it still returns distances */
+func ScaleDistanceRelation(rel Relation, scale float64) Relation {
+    return MapRelation(ScaleRelation(rel, scale), func (d float64) float64 {
+        return d * scale
+    })
+}
+
+/* Grows a shape outward by the given radius, rounding its corners */
+func RoundRelation(rel Relation, radius float64) Relation {
+    return MapRelation(rel, func (d float64) float64 {
+        return d - radius
+    })
+}
+
+/* Turns a shape into an outline of itself with the given thickness */
+func OutlineRelation(rel Relation, thickness float64) Relation {
+    return MapRelation(rel, func (d float64) float64 {
+        return math.Abs(d) - thickness / 2
+    })
+}
+
+/*
+    The polynomial smooth minimum of two distances,
+    blending them within k of each other.
+*/
+func smoothMin(a, b, k float64) float64 {
+    h := math.Max(0, math.Min(1, 0.5 + 0.5 * (b - a) / k))
+
+    return b + h * (a - b) - k * h * (1 - h)
+}
+
+/*
+    Like UnionRelation, but with the join between the
+    shapes smoothed over a distance of k. If either
+    Relation doesn't return a float64, this is the same
+    as a normal union.
+*/
+func SmoothUnionRelation(rel0, rel1 Relation, k float64) Relation {
+    return CombineRelations(rel0, rel1, func (a, b bool) bool {
+        return a || b
+    }, func (a, b float64) float64 {
+        return smoothMin(a, b, k)
+    })
+}
+
+func SmoothIntersectionRelation(rel0, rel1 Relation, k float64) Relation {
+    return CombineRelations(rel0, rel1, func (a, b bool) bool {
+        return a && b
+    }, func (a, b float64) float64 {
+        return -smoothMin(-a, -b, k)
+    })
+}
+
+func SmoothDifferenceRelation(rel0, rel1 Relation, k float64) Relation {
+    return CombineRelations(rel0, rel1, func (a, b bool) bool {
+        return a && !b
+    }, func (a, b float64) float64 {
+        return -smoothMin(-a, b, k)
+    })
+}
+
+func Disk(r float64) Relation {
+    return func (c *Coord) interface{} {
+        return c.DistOrigin() - r
+    }
+}
+
+/* A rectangle centered on the origin */
+func Rectangle(width, height float64) Relation {
+    return func (c *Coord) interface{} {
+        dx := math.Abs(c.X) - width / 2
+        dy := math.Abs(c.Y) - height / 2
+
+        outside := math.Hypot(math.Max(dx, 0), math.Max(dy, 0))
+        inside := math.Min(math.Max(dx, dy), 0)
+
+        return outside + inside
+    }
+}
+
+/* A rectangle centered on the origin with corners of the given radius */
+func RoundedBox(width, height, radius float64) Relation {
+    return RoundRelation(Rectangle(width - 2 * radius, height - 2 * radius), radius)
+}
+
+/*
+    A polygon with the given vertices in order.
+    The polygon does not need to be convex, but
+    its edges should not cross each other.
+*/
+func Polygon(p0, p1, p2 *Coord, rest ...*Coord) Relation {
+    return polygon(append([]*Coord{p0, p1, p2}, rest...))
+}
+
+/* Like Polygon, but for vertices that are already in a slice, of which there must be at least 3 */
+func polygon(points []*Coord) Relation {
+    return func (c *Coord) interface{} {
+        dist := c.Sub(points[0]).Dot(c.Sub(points[0]))
+        sign := 1.0
+
+        for i, j := 0, len(points) - 1; i < len(points); j, i = i, i + 1 {
+            edge := points[j].Sub(points[i])
+            w := c.Sub(points[i])
+
+            t := math.Max(0, math.Min(1, w.Dot(edge) / edge.Dot(edge)))
+            b := w.Sub(edge.Mult(t))
+            dist = math.Min(dist, b.Dot(b))
+
+            /* Flip the sign for every edge crossed by a ray going right */
+            above := c.Y >= points[i].Y
+            below := c.Y < points[j].Y
+            left := edge.X * w.Y > edge.Y * w.X
+
+            if (above && below && left) || (!above && !below && !left) {
+                sign = -sign
+            }
+        }
+
+        return sign * math.Sqrt(dist)
+    }
+}
+
+/*
+    A regular polygon centered on the origin with n sides
+    and the given circumradius, with a vertex pointing up.
+    Less than 3 sides are treated as 3.
+*/
+func RegularPolygon(n int, r float64) Relation {
+    n = MaxInt(n, 3)
+    points := make([]*Coord, n)
+
+    for i := range points {
+        points[i] = NewCoordFromPolar(r, math.Pi / 2 + 2 * math.Pi * float64(i) / float64(n))
+    }
+
+    return polygon(points)
+}
+
+/*
+    A star centered on the origin with n points, whose
+    points are r_outer away from the center and whose
+    inner corners are r_inner away, with a point pointing up.
+    Less than 2 points are treated as 2.
+*/
+func Star(n int, r_outer, r_inner float64) Relation {
+    n = MaxInt(n, 2)
+    points := make([]*Coord, 2 * n)
+
+    for i := range points {
+        r := r_outer
+        if i % 2 == 1 {
+            r = r_inner
+        }
+
+        points[i] = NewCoordFromPolar(r, math.Pi / 2 + math.Pi * float64(i) / float64(n))
+    }
+
+    return polygon(points)
+}
+
+/* A line segment from c0 to c1 with the given thickness and rounded ends */
+func Segment(c0, c1 *Coord, thickness float64) Relation {
+    return func (c *Coord) interface{} {
+        edge := c1.Sub(c0)
+        w := c.Sub(c0)
+
+        t := math.Max(0, math.Min(1, w.Dot(edge) / edge.Dot(edge)))
+
+        return w.Sub(edge.Mult(t)).DistOrigin() - thickness / 2
+    }
+}
+
+/* A ring centered on the origin between the inner and outer radii */
+func Annulus(r_inner, r_outer float64) Relation {
+    return OutlineRelation(Disk((r_inner + r_outer) / 2), r_outer - r_inner)
+}
+
+/* Wraps an angle to be between 0 and 2π */
+func wrapAngle(theta float64) float64 {
+    theta = math.Mod(theta, 2 * math.Pi)
+    if theta < 0 {
+        theta += 2 * math.Pi
+    }
+
+    return theta
+}
+
+/*
+    An arc of a circle centered on the origin, going
+    counterclockwise from the start angle to the end angle,
+    with the given thickness and rounded ends. The angles
+    can be in any range, and the arc is a full circle if
+    they are at least a full turn apart.
+*/
+func Arc(r, start, end, thickness float64) Relation {
+    full := math.Abs(end - start) >= 2 * math.Pi
+    sweep := wrapAngle(end - start)
+    start = wrapAngle(start)
+
+    c0, c1 := NewCoordFromPolar(r, start), NewCoordFromPolar(r, start + sweep)
+
+    return func (c *Coord) interface{} {
+        _, theta := c.Polar()
+
+        if full || wrapAngle(theta - start) <= sweep {
+            return math.Abs(c.DistOrigin() - r) - thickness / 2
+        }
+
+        return math.Min(c.Dist(c0), c.Dist(c1)) - thickness / 2
+    }
+}
\ No newline at end of file
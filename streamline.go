@@ -0,0 +1,323 @@
+package gograph
+
+import (
+    "math"
+    "image/color"
+)
+
+/*
+    How streamlines are placed, using the algorithm of
+    Jobard and Lefer. Streamlines are kept Separation
+    apart from each other where they start, and stop once
+    they come within TestRatio times that of another
+    streamline. Streamlines shorter than MinLength are
+    thrown away.
+
+    Streamlines are traced in steps of Step, and if Arrows
+    is set, an arrowhead is drawn in the middle of each
+    streamline to show the direction of the flow.
+*/
+type StreamlineStyle struct {
+    Separation, TestRatio float64
+    Step, MinLength float64
+    Arrows bool
+}
+
+/*
+    A grid of cells that points are sorted into, used
+    to quickly find whether a point is near any others.
+*/
+type pointGrid struct {
+    Bounds *Area
+    CellSize float64
+    Cols, Rows int
+    Cells [][]*Coord
+}
+
+func NewStreamlineStyle(separation, step float64) *StreamlineStyle {
+    return &StreamlineStyle{separation, 0.5, step, separation, true}
+}
+
+/*
+    The number of points of a streamline too recent to
+    have drifted away from the point being traced, which
+    aren't checked against when looking for where it
+    comes back around near itself.
+*/
+func (s *StreamlineStyle) recentPoints() int {
+    return 2 * int(math.Ceil(s.TestRatio * s.Separation / s.Step)) + 1
+}
+
+func newPointGrid(bounds *Area, cell_size float64) *pointGrid {
+    cols := int(math.Ceil(bounds.Width() / cell_size)) + 1
+    rows := int(math.Ceil(bounds.Height() / cell_size)) + 1
+
+    return &pointGrid{bounds, cell_size, cols, rows, make([][]*Coord, cols * rows)}
+}
+
+func (pg *pointGrid) cell(c *Coord) (int, int) {
+    return int((c.X - pg.Bounds.Pos0.X) / pg.CellSize), int((pg.Bounds.Pos0.Y - c.Y) / pg.CellSize)
+}
+
+func (pg *pointGrid) Add(c *Coord) {
+    x, y := pg.cell(c)
+    if x < 0 || x >= pg.Cols || y < 0 || y >= pg.Rows {
+        return
+    }
+
+    pg.Cells[y * pg.Cols + x] = append(pg.Cells[y * pg.Cols + x], c)
+}
+
+/* Whether any point in the grid is within dist of a coordinate */
+func (pg *pointGrid) Near(c *Coord, dist float64) bool {
+    x, y := pg.cell(c)
+    reach := int(math.Ceil(dist / pg.CellSize))
+
+    for i := MaxInt(x - reach, 0); i <= MinInt(x + reach, pg.Cols - 1); i++ {
+        for j := MaxInt(y - reach, 0); j <= MinInt(y + reach, pg.Rows - 1); j++ {
+            for _, other := range pg.Cells[j * pg.Cols + i] {
+                if c.WithinDist(other, dist) {
+                    return true
+                }
+            }
+        }
+    }
+
+    return false
+}
+
+/* Gets the direction of a vector field, or nil if it has none */
+func (v VectorField) direction(c *Coord) *Coord {
+    vec := v(c)
+    mag := vec.DistOrigin()
+
+    if !vec.IsValid() || mag == 0 {
+        return nil
+    }
+
+    return vec.Div(mag)
+}
+
+/* Takes a step along the direction of a vector field using RK4 */
+func (v VectorField) stepRK4(c *Coord, h float64) *Coord {
+    k1 := v.direction(c)
+    if k1 == nil {
+        return nil
+    }
+
+    k2 := v.direction(c.Add(k1.Mult(h / 2)))
+    if k2 == nil {
+        return nil
+    }
+
+    k3 := v.direction(c.Add(k2.Mult(h / 2)))
+    if k3 == nil {
+        return nil
+    }
+
+    k4 := v.direction(c.Add(k3.Mult(h)))
+    if k4 == nil {
+        return nil
+    }
+
+    return c.Add(k1.Add(k2.Mult(2)).Add(k3.Mult(2)).Add(k4).Mult(h / 6))
+}
+
+/*
+    Traces a streamline from a seed in one direction, stopping
+    once it leaves the bounds, reaches a point where the field
+    has no direction, or comes back around near itself.
+*/
+func (g *Graph) traceStreamlineDirection(v VectorField, seed *Coord, h float64, style *StreamlineStyle, own *pointGrid) []*Coord {
+    dtest := style.TestRatio * style.Separation
+    delay := style.recentPoints()
+    max_steps := int(4 * (g.Bounds.Width() + g.Bounds.Height()) / math.Abs(h))
+
+    points := []*Coord{seed}
+
+    for i := 0; i < max_steps; i++ {
+        if i >= delay {
+            own.Add(points[i - delay])
+        }
+
+        next := v.stepRK4(points[i], h)
+        if next == nil || !g.Bounds.Contains(next) || own.Near(next, dtest) {
+            break
+        }
+
+        points = append(points, next)
+    }
+
+    return points
+}
+
+/*
+    Traces a whole streamline through a seed, returning
+    its points in the direction of the flow along with
+    the index of the seed within them.
+*/
+func (g *Graph) TraceStreamline(v VectorField, seed *Coord, style *StreamlineStyle) ([]*Coord, int) {
+    forward := g.traceStreamlineDirection(v, seed, style.Step, style, newPointGrid(g.Bounds, style.Separation))
+
+    /* Going backward shouldn't stop right away from being near the start of going forward */
+    own := newPointGrid(g.Bounds, style.Separation)
+    for i := style.recentPoints(); i < len(forward); i++ {
+        own.Add(forward[i])
+    }
+
+    backward := g.traceStreamlineDirection(v, seed, -style.Step, style, own)
+
+    points := make([]*Coord, 0, len(forward) + len(backward) - 1)
+    for i := len(backward) - 1; i > 0; i-- {
+        points = append(points, backward[i])
+    }
+
+    return append(points, forward...), len(backward) - 1
+}
+
+func (g *Graph) TraceStreamlinesInChunk(v VectorField, seeds []*Coord, style *StreamlineStyle, lines [][]*Coord, seed_indices []int, ch chan struct{}) {
+    for i, seed := range seeds {
+        lines[i], seed_indices[i] = g.TraceStreamline(v, seed, style)
+    }
+
+    ch <- struct{}{}
+}
+
+/*
+    Cuts a streamline down to the part around its seed that
+    stays far enough away from the streamlines already accepted,
+    returning nil if what's left is too short to keep.
+*/
+func acceptStreamline(points []*Coord, seed int, accepted *pointGrid, style *StreamlineStyle) []*Coord {
+    dtest := style.TestRatio * style.Separation
+
+    if accepted.Near(points[seed], style.Separation) {
+        return nil
+    }
+
+    start := seed
+    for start > 0 && !accepted.Near(points[start - 1], dtest) {
+        start--
+    }
+
+    end := seed
+    for end < len(points) - 1 && !accepted.Near(points[end + 1], dtest) {
+        end++
+    }
+
+    points = points[start:end + 1]
+
+    length := 0.0
+    for i := 1; i < len(points); i++ {
+        length += points[i].Dist(points[i - 1])
+    }
+
+    if length < style.MinLength {
+        return nil
+    }
+
+    return points
+}
+
+/* Gets the seeds a separation away on either side of a streamline */
+func streamlineSeeds(points []*Coord, style *StreamlineStyle) []*Coord {
+    var seeds []*Coord
+
+    every := MaxInt(int(style.Separation / style.Step), 1)
+
+    for i := every; i < len(points); i += every {
+        dir := points[i].Sub(points[i - 1])
+        dir = NewCoord(-dir.Y, dir.X).Mult(style.Separation / dir.DistOrigin())
+
+        seeds = append(seeds, points[i].Add(dir), points[i].Sub(dir))
+    }
+
+    return seeds
+}
+
+func (g *Graph) DrawStreamlineInChunk(lines [][]*Coord, style *StreamlineStyle, col color.Color, ch chan struct{}) {
+    for _, line := range lines {
+        for i := 1; i < len(line); i++ {
+            g.DrawLine(line[i - 1], line[i], col)
+        }
+
+        if style.Arrows && len(line) > 1 {
+            mid := len(line) / 2
+            g.DrawArrowHead(line[MaxInt(mid - 3, 0)], line[mid], col)
+        }
+    }
+
+    ch <- struct{}{}
+}
+
+/*
+    Draws evenly spaced streamlines of a vector field.
+    Streamlines are traced in rounds, with every candidate
+    streamline of a round being traced in parallel before
+    being accepted or cut short in order, and with the
+    accepted streamlines seeding the next round.
+*/
+func (g *Graph) DrawStreamlinesWithColor(v VectorField, style *StreamlineStyle, col color.Color) {
+    accepted := newPointGrid(g.Bounds, style.Separation)
+
+    /* Start with a coarse grid of seeds, which later rounds fill in */
+    seeds := g.vectorFieldPoints(3 * style.Separation, false)
+
+    for len(seeds) > 0 {
+        lines := make([][]*Coord, len(seeds))
+        seed_indices := make([]int, len(seeds))
+
+        var channels []chan struct{}
+
+        for i := 0; i < len(seeds); i += ChunkSize {
+            ch := make(chan struct{})
+            channels = append(channels, ch)
+
+            end := MinInt(i + ChunkSize, len(seeds))
+            go g.TraceStreamlinesInChunk(v, seeds[i:end], style, lines[i:end], seed_indices[i:end], ch)
+        }
+
+        for _, ch := range channels {
+            <-ch
+        }
+
+        var new_lines [][]*Coord
+        seeds = nil
+
+        for i, line := range lines {
+            line = acceptStreamline(line, seed_indices[i], accepted, style)
+            if line == nil {
+                continue
+            }
+
+            for _, c := range line {
+                accepted.Add(c)
+            }
+
+            new_lines = append(new_lines, line)
+
+            for _, seed := range streamlineSeeds(line, style) {
+                if g.Bounds.Contains(seed) && !accepted.Near(seed, style.Separation) {
+                    seeds = append(seeds, seed)
+                }
+            }
+        }
+
+        channels = nil
+
+        for i := 0; i < len(new_lines); i += ChunkSize {
+            ch := make(chan struct{})
+            channels = append(channels, ch)
+
+            go g.DrawStreamlineInChunk(new_lines[i:MinInt(i + ChunkSize, len(new_lines))], style, col, ch)
+        }
+
+        for _, ch := range channels {
+            <-ch
+        }
+    }
+}
+
+func (g *Graph) DrawStreamlines(v VectorField, style *StreamlineStyle) {
+    g.DrawStreamlinesWithColor(v, style, g.RelationColor)
+}
\ No newline at end of file
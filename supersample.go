@@ -0,0 +1,269 @@
+package gograph
+
+import (
+    "math"
+    "sync"
+    "image"
+    "image/color"
+)
+
+/* Where the samples of a supersampled pixel are placed */
+type SamplePattern int
+
+const (
+    /* Samples are placed on a regular grid */
+    RegularSamples SamplePattern = iota
+
+    /*
+        Samples are placed on a grid that is rotated,
+        so that no two samples share a row or column,
+        which does better on nearly horizontal and
+        nearly vertical edges.
+    */
+    RotatedSamples
+
+    /*
+        Samples are placed randomly within each cell
+        of a regular grid, trading aliasing for noise.
+    */
+    StratifiedSamples
+)
+
+/*
+    How pixels get supersampled, taking Factor by
+    Factor samples of each pixel. If Adaptive is set,
+    only pixels on the edge of a region will be
+    supersampled, which is much faster but can miss
+    details smaller than a pixel.
+*/
+type Supersampling struct {
+    Factor int
+    Pattern SamplePattern
+    Adaptive bool
+}
+
+/* Accumulates the colors that get mapped onto each pixel of an image */
+type colorAccumulator struct {
+    Width int
+    Sums []float64
+    Weights []float64
+
+    /* One lock for each row of pixels */
+    Locks []sync.Mutex
+}
+
+/* Makes a Supersampling, with a factor less than 1 being treated as 1 */
+func NewSupersampling(factor int, pattern SamplePattern, adaptive bool) *Supersampling {
+    return &Supersampling{MaxInt(factor, 1), pattern, adaptive}
+}
+
+/* A cheap hash of a pixel's position, so its samples don't depend on drawing order */
+func pixelHash(pt image.Point, i int) float64 {
+    h := uint32(pt.X) * 0x8DA6B343 ^ uint32(pt.Y) * 0xD8163841 ^ uint32(i) * 0xCB1AB31F
+    h ^= h >> 15
+    h *= 0x2C1B3C6D
+    h ^= h >> 12
+
+    return float64(h) / (1 << 32)
+}
+
+/*
+    Gets the offsets of the samples of a pixel from its
+    top left corner. A Factor less than 1 is treated as 1.
+*/
+func (s *Supersampling) Offsets(pt image.Point) []*Coord {
+    n := MaxInt(s.Factor, 1)
+    offsets := make([]*Coord, 0, n * n)
+
+    for i := 0; i < n; i++ {
+        for j := 0; j < n; j++ {
+            off := NewCoord((float64(i) + 0.5) / float64(n), (float64(j) + 0.5) / float64(n))
+
+            switch s.Pattern {
+                case RotatedSamples:
+                    off = off.RotateAround(math.Atan(0.5), NewCoord(0.5, 0.5))
+                    off = NewCoord(off.X - math.Floor(off.X), off.Y - math.Floor(off.Y))
+
+                case StratifiedSamples:
+                    off = NewCoord(
+                        (float64(i) + pixelHash(pt, 2 * len(offsets))) / float64(n),
+                        (float64(j) + pixelHash(pt, 2 * len(offsets) + 1)) / float64(n),
+                    )
+            }
+
+            offsets = append(offsets, off)
+        }
+    }
+
+    return offsets
+}
+
+/*
+    Gets how much of a pixel is inside a Relation, where
+    inside is whether the top left corner of the pixel is.
+*/
+func (g *Graph) relationCoverage(rel Relation, pt image.Point, inside bool) (float64, error) {
+    if g.Supersampling.Adaptive {
+        edge := false
+
+        for _, off := range [3]image.Point{image.Pt(1, 0), image.Pt(0, 1), image.Pt(1, 1)} {
+            val := rel(g.PixelToCoord(pt.Add(off)))
+            if err, ok := val.(error); ok {
+                return 0, err
+            }
+
+            if IsInside(val) != inside {
+                edge = true
+                break
+            }
+        }
+
+        if !edge {
+            if inside {
+                return 1, nil
+            }
+
+            return 0, nil
+        }
+    }
+
+    offsets := g.Supersampling.Offsets(pt)
+    count := 0
+
+    for _, off := range offsets {
+        val := rel(g.SubpixelToCoord(float64(pt.X) + off.X, float64(pt.Y) + off.Y))
+        if err, ok := val.(error); ok {
+            return 0, err
+        }
+
+        if IsInside(val) {
+            count++
+        }
+    }
+
+    return float64(count) / float64(len(offsets)), nil
+}
+
+func newColorAccumulator(width, height int) *colorAccumulator {
+    return &colorAccumulator{
+        width,
+        make([]float64, 4 * width * height),
+        make([]float64, width * height),
+        make([]sync.Mutex, height),
+    }
+}
+
+func (a *colorAccumulator) Add(pt image.Point, col color.Color, weight float64) {
+    r, g, b, alpha := col.RGBA()
+    i := pt.Y * a.Width + pt.X
+
+    a.Locks[pt.Y].Lock()
+
+    a.Sums[4 * i] += weight * float64(r)
+    a.Sums[4 * i + 1] += weight * float64(g)
+    a.Sums[4 * i + 2] += weight * float64(b)
+    a.Sums[4 * i + 3] += weight * float64(alpha)
+    a.Weights[i] += weight
+
+    a.Locks[pt.Y].Unlock()
+}
+
+/*
+    Averages the colors mapped onto each pixel, with
+    the pixels that were only partly covered by them
+    being made that much more transparent.
+*/
+func (a *colorAccumulator) Image(bounds image.Rectangle) *image.RGBA {
+    img := image.NewRGBA(bounds)
+
+    for i, weight := range a.Weights {
+        if weight == 0 {
+            continue
+        }
+
+        scale := math.Min(weight, 1) / weight
+
+        img.SetRGBA64(i % a.Width, i / a.Width, color.RGBA64{
+            uint16(a.Sums[4 * i] * scale),
+            uint16(a.Sums[4 * i + 1] * scale),
+            uint16(a.Sums[4 * i + 2] * scale),
+            uint16(a.Sums[4 * i + 3] * scale),
+        })
+    }
+
+    return img
+}
+
+/* Whether a pixel has a different color than the pixels to its right and below it */
+func (g *Graph) isEdgePixel(pt image.Point) bool {
+    col := g.AtPixel(pt)
+
+    for _, off := range [2]image.Point{image.Pt(1, 0), image.Pt(0, 1)} {
+        other := pt.Add(off)
+
+        if other.In(g.Image.Bounds()) && g.AtPixel(other) != col {
+            return true
+        }
+    }
+
+    return false
+}
+
+func (g *Graph) ApplyComplexRelationSupersampledInChunk(rel ComplexRelation, acc *colorAccumulator, r *image.Rectangle, ch chan struct{}) {
+    for x := r.Min.X; x < r.Max.X; x++ {
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            pt := image.Pt(x, y)
+            col := g.AtPixel(pt)
+
+            offsets := []*Coord{NewCoord(0, 0)}
+            if !g.Supersampling.Adaptive || g.isEdgePixel(pt) {
+                offsets = g.Supersampling.Offsets(pt)
+            }
+
+            weight := 1 / float64(len(offsets))
+
+            for _, off := range offsets {
+                c := g.SubpixelToCoord(float64(x) + off.X, float64(y) + off.Y)
+
+                new_z := rel(complex(c.X, c.Y))
+                new_c := NewCoord(real(new_z), imag(new_z))
+
+                if g.Bounds.Contains(new_c) {
+                    acc.Add(g.CoordToPixel(new_c), col, weight)
+                }
+            }
+        }
+    }
+
+    ch <- struct{}{}
+}
+
+/*
+    Like ApplyComplexRelation, but maps each sample of
+    a pixel separately, so that the coverage of each pixel
+    being mapped onto becomes its alpha.
+*/
+func (g *Graph) ApplyComplexRelationSupersampled(rel ComplexRelation) {
+    acc := newColorAccumulator(g.ImageWidth(), g.ImageHeight())
+
+    var channels []chan struct{}
+
+    for x := 0; x < g.ImageWidth(); x += ChunkSize {
+        for y := 0; y < g.ImageHeight(); y += ChunkSize {
+            ch := make(chan struct {})
+            channels = append(channels, ch)
+
+            r := image.Rect(x, y, MinInt(x + ChunkSize, g.ImageWidth()), MinInt(y + ChunkSize, g.ImageHeight()))
+            go g.ApplyComplexRelationSupersampledInChunk(rel, acc, &r, ch)
+        }
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+
+    img := acc.Image(g.Image.Bounds())
+
+    g.Image = img
+    g.layer.Image = img
+}
\ No newline at end of file
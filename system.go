@@ -0,0 +1,458 @@
+package gograph
+
+import (
+    "math"
+    "math/cmplx"
+    "image"
+    "image/color"
+)
+
+const (
+    /* The radius in pixels of the markers drawn at equilibria */
+    EquilibriumRadius = 4.0
+
+    /* The width in pixels of the outline of the markers of unstable equilibria */
+    EquilibriumOutline = 1.5
+
+    /* The most iterations of Newton's method used to find an equilibrium */
+    EquilibriumIterations = 50
+)
+
+/* The kind of an equilibrium, based on the linearization of a System there */
+type EquilibriumKind int
+
+const (
+    /* Trajectories come in along one direction and leave along another */
+    SaddlePoint EquilibriumKind = iota
+
+    /* Trajectories come straight in */
+    StableNode
+
+    /* Trajectories go straight out */
+    UnstableNode
+
+    /* Trajectories spiral in */
+    StableSpiral
+
+    /* Trajectories spiral out */
+    UnstableSpiral
+
+    /*
+        Trajectories circle around. Only the linearization
+        is certain to have closed orbits, and the System
+        itself might spiral in or out very slowly.
+    */
+    Center
+
+    /* The Jacobian is singular, so the linearization can't tell */
+    DegenerateEquilibrium
+)
+
+/*
+    A point where a System doesn't change, along with
+    its Jacobian and the eigenvalues of the Jacobian.
+*/
+type Equilibrium struct {
+    Pos *Coord
+    Kind EquilibriumKind
+
+    Jacobian [2][2]float64
+    Eigenvalues [2]complex128
+}
+
+/*
+    How a phase portrait is drawn. Nullclines and equilibria
+    aren't drawn where their color is nil. The equilibria are
+    searched for from a grid of Resolution by Resolution points.
+
+    If Arrows is set, an arrowhead is drawn at each seed of a
+    trajectory to show which way the System goes.
+*/
+type PhasePortraitStyle struct {
+    TrajectoryColor color.Color
+    XNullclineColor, YNullclineColor color.Color
+    EquilibriumColor color.Color
+
+    Arrows bool
+    Integrator Integrator
+    Resolution int
+}
+
+var (
+    /* The default color of the nullclines where dx/dt is 0 */
+    DefaultXNullclineColor = color.RGBA{0xFF, 0x80, 0x00, 0xFF}
+
+    /* The default color of the nullclines where dy/dt is 0 */
+    DefaultYNullclineColor = color.RGBA{0x00, 0xA0, 0x00, 0xFF}
+)
+
+func (k EquilibriumKind) String() string {
+    switch k {
+        case SaddlePoint:
+            return "Saddle point"
+
+        case StableNode:
+            return "Stable node"
+
+        case UnstableNode:
+            return "Unstable node"
+
+        case StableSpiral:
+            return "Stable spiral"
+
+        case UnstableSpiral:
+            return "Unstable spiral"
+
+        case Center:
+            return "Center"
+    }
+
+    return "Degenerate equilibrium"
+}
+
+/* Whether nearby trajectories stay nearby */
+func (k EquilibriumKind) IsStable() bool {
+    return k == StableNode || k == StableSpiral || k == Center
+}
+
+func NewPhasePortraitStyle(col color.Color) *PhasePortraitStyle {
+    return &PhasePortraitStyle{col, DefaultXNullclineColor, DefaultYNullclineColor, col, true, DefaultIntegrator, 20}
+}
+
+func (s System) ToODE() ODE {
+    return func (t float64, y []float64) []float64 {
+        v := s(NewCoord(y[0], y[1]))
+        if v == nil {
+            return nil
+        }
+
+        return []float64{v.X, v.Y}
+    }
+}
+
+func (s System) ToVectorField() VectorField {
+    return VectorField(s)
+}
+
+/*
+    Converts a System to an ODE that goes along its trajectories
+    at a speed of 1, so that t measures the length traveled.
+    This keeps trajectories from slowing to a crawl as they
+    approach an equilibrium.
+*/
+func (s System) unitSpeedODE() ODE {
+    return func (t float64, y []float64) []float64 {
+        v := s(NewCoord(y[0], y[1]))
+        if v == nil {
+            return nil
+        }
+
+        speed := v.DistOrigin()
+
+        return []float64{v.X / speed, v.Y / speed}
+    }
+}
+
+/*
+    Returns a Relation whose zero set is where dx/dt is 0.
+    This is where trajectories go straight up or down.
+*/
+func (s System) XNullcline() Relation {
+    return func (c *Coord) interface{} {
+        v := s(c)
+        if v == nil {
+            return math.NaN()
+        }
+
+        return v.X
+    }
+}
+
+/*
+    Returns a Relation whose zero set is where dy/dt is 0.
+    This is where trajectories go straight left or right.
+*/
+func (s System) YNullcline() Relation {
+    return func (c *Coord) interface{} {
+        v := s(c)
+        if v == nil {
+            return math.NaN()
+        }
+
+        return v.Y
+    }
+}
+
+/* Approximates the Jacobian of a System with central differences */
+func (s System) Jacobian(c *Coord) [2][2]float64 {
+    h := 1e-6 * math.Max(1, c.DistOrigin())
+
+    eval := func (c *Coord) *Coord {
+        if v := s(c); v != nil {
+            return v
+        }
+
+        return NewCoord(math.NaN(), math.NaN())
+    }
+
+    dx := eval(c.Add(NewCoord(h, 0))).Sub(eval(c.Sub(NewCoord(h, 0)))).Div(2 * h)
+    dy := eval(c.Add(NewCoord(0, h))).Sub(eval(c.Sub(NewCoord(0, h)))).Div(2 * h)
+
+    return [2][2]float64 {
+        {dx.X, dy.X},
+        {dx.Y, dy.Y},
+    }
+}
+
+/*
+    Gets the eigenvalues of a 2x2 matrix,
+    with the one with the larger real part first.
+*/
+func Eigenvalues(m [2][2]float64) [2]complex128 {
+    trace := m[0][0] + m[1][1]
+    det := m[0][0] * m[1][1] - m[0][1] * m[1][0]
+
+    root := cmplx.Sqrt(complex(trace * trace - 4 * det, 0))
+
+    return [2]complex128{(complex(trace, 0) + root) / 2, (complex(trace, 0) - root) / 2}
+}
+
+/*
+    Classifies an equilibrium by the trace and determinant
+    of the Jacobian there. Values that are tiny compared to
+    the size of the Jacobian are treated as 0.
+*/
+func ClassifyEquilibrium(jac [2][2]float64) EquilibriumKind {
+    trace := jac[0][0] + jac[1][1]
+    det := jac[0][0] * jac[1][1] - jac[0][1] * jac[1][0]
+
+    size := math.Abs(jac[0][0]) + math.Abs(jac[0][1]) + math.Abs(jac[1][0]) + math.Abs(jac[1][1])
+    eps := 1e-6 * size
+
+    if size == 0 || math.Abs(det) <= eps * size {
+        return DegenerateEquilibrium
+    }
+
+    if det < 0 {
+        return SaddlePoint
+    }
+
+    if trace * trace < 4 * det {
+        switch {
+            case math.Abs(trace) <= eps:
+                return Center
+
+            case trace < 0:
+                return StableSpiral
+        }
+
+        return UnstableSpiral
+    }
+
+    if trace < 0 {
+        return StableNode
+    }
+
+    return UnstableNode
+}
+
+/*
+    Finds an equilibrium of a System near a point using
+    Newton's method, returning nil if it doesn't converge.
+*/
+func (s System) findEquilibrium(c *Coord) *Coord {
+    for i := 0; i < EquilibriumIterations; i++ {
+        v := s(c)
+        if v == nil || !v.IsValid() {
+            return nil
+        }
+
+        jac := s.Jacobian(c)
+
+        step := solveLinear(
+            [][]float64{{jac[0][0], jac[0][1]}, {jac[1][0], jac[1][1]}},
+            []float64{-v.X, -v.Y},
+        )
+
+        if step == nil {
+            return nil
+        }
+
+        c = c.Add(NewCoord(step[0], step[1]))
+        if !c.IsValid() {
+            return nil
+        }
+
+        if math.Hypot(step[0], step[1]) <= 1e-12 * math.Max(1, c.DistOrigin()) {
+            return c
+        }
+    }
+
+    return nil
+}
+
+func (s System) findEquilibriaInChunk(bounds *Area, cell_size *Coord, r *image.Rectangle, ch chan []*Coord) {
+    var found []*Coord
+
+    for x := r.Min.X; x < r.Max.X; x++ {
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            /* Start from the center of each cell */
+            start := cellCorner(bounds, cell_size, x, y).Add(NewCoord(cell_size.X, -cell_size.Y).Div(2))
+
+            if c := s.findEquilibrium(start); c != nil && bounds.Contains(c) {
+                found = append(found, c)
+            }
+        }
+    }
+
+    ch <- found
+}
+
+/*
+    Finds the equilibria of a System within the bounds by
+    starting Newton's method from the centers of a grid of
+    resolution by resolution cells, in chunks across goroutines.
+*/
+func (s System) FindEquilibria(bounds *Area, resolution int) []*Equilibrium {
+    cell_size := NewCoord(bounds.Width() / float64(resolution), bounds.Height() / float64(resolution))
+
+    var channels []chan []*Coord
+
+    for x := 0; x < resolution; x += ChunkSize {
+        for y := 0; y < resolution; y += ChunkSize {
+            ch := make(chan []*Coord)
+            channels = append(channels, ch)
+
+            r := image.Rect(x, y, MinInt(x + ChunkSize, resolution), MinInt(y + ChunkSize, resolution))
+            go s.findEquilibriaInChunk(bounds, cell_size, &r, ch)
+        }
+    }
+
+    /* Newton's method finds the same equilibria from many starting points */
+    same_dist := 1e-6 * math.Max(bounds.Width(), bounds.Height())
+
+    var equilibria []*Equilibrium
+
+    for _, ch := range channels {
+    found:
+        for _, c := range <-ch {
+            for _, e := range equilibria {
+                if c.WithinDist(e.Pos, same_dist) {
+                    continue found
+                }
+            }
+
+            jac := s.Jacobian(c)
+
+            equilibria = append(equilibria, &Equilibrium{c, ClassifyEquilibrium(jac), jac, Eigenvalues(jac)})
+        }
+    }
+
+    return equilibria
+}
+
+/*
+    Draws a marker at an equilibrium, which is a filled
+    dot if the equilibrium is stable and a hollow one if not.
+*/
+func (g *Graph) DrawEquilibrium(e *Equilibrium, col color.Color) {
+    if e.Kind.IsStable() {
+        g.DrawDot(e.Pos, EquilibriumRadius, col)
+        return
+    }
+
+    g.DrawHollowDot(e.Pos, EquilibriumRadius, EquilibriumOutline, col)
+}
+
+/*
+    Draws the trajectory of a System through a point, both
+    forwards and backwards in time, until it stops at an
+    equilibrium or has gone as far as four times around the
+    edge of the graph, such as when going around a cycle.
+*/
+func (g *Graph) DrawTrajectoryWithIntegrator(s System, start *Coord, integ Integrator, col color.Color) {
+    f := s.unitSpeedODE()
+    h := g.Bounds.Width() / float64(g.ImageWidth())
+    length := 4 * (g.Bounds.Width() + g.Bounds.Height())
+
+    point := func (t float64, y []float64) *Coord {
+        return NewCoord(y[0], y[1])
+    }
+
+    channels := [2]chan struct{} {
+        make(chan struct{}),
+        make(chan struct{}),
+    }
+
+    for i, end := range [2]float64{length, -length} {
+        go func (end float64, ch chan struct{}) {
+            g.DrawODESolution(f, integ, 0, end, []float64{start.X, start.Y}, h, point, col)
+
+            ch <- struct{}{}
+        }(end, channels[i])
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+}
+
+func (g *Graph) DrawTrajectoryWithColor(s System, start *Coord, col color.Color) {
+    g.DrawTrajectoryWithIntegrator(s, start, DefaultIntegrator, col)
+}
+
+func (g *Graph) DrawTrajectory(s System, start *Coord) {
+    g.DrawTrajectoryWithColor(s, start, g.RelationColor)
+}
+
+/* Draws an arrowhead at a point pointing the way a System goes */
+func (g *Graph) drawFlowArrowHead(s System, c *Coord, col color.Color) {
+    v := s(c)
+    if v == nil || !v.IsValid() || v.DistOrigin() == 0 {
+        return
+    }
+
+    tip := g.CoordToSubpixel(c)
+
+    dir := g.CoordToSubpixel(c.Add(v)).Sub(tip)
+    tail := tip.Sub(dir.Mult(2 * ArrowHeadSize / dir.DistOrigin()))
+
+    g.DrawArrowHead(g.SubpixelToCoord(tail.X, tail.Y), c, col)
+}
+
+/*
+    Draws the phase portrait of a System, with the trajectories
+    through each of the seeds, the nullclines, and the equilibria
+    within the graph, which are returned along with their kinds.
+*/
+func (g *Graph) DrawPhasePortraitWithStyle(s System, style *PhasePortraitStyle, seeds ...*Coord) []*Equilibrium {
+    if style.XNullclineColor != nil {
+        g.DrawRelationWithColor(s.XNullcline(), style.XNullclineColor)
+    }
+
+    if style.YNullclineColor != nil {
+        g.DrawRelationWithColor(s.YNullcline(), style.YNullclineColor)
+    }
+
+    for _, seed := range seeds {
+        g.DrawTrajectoryWithIntegrator(s, seed, style.Integrator, style.TrajectoryColor)
+
+        if style.Arrows {
+            g.drawFlowArrowHead(s, seed, style.TrajectoryColor)
+        }
+    }
+
+    equilibria := s.FindEquilibria(g.Bounds, style.Resolution)
+
+    if style.EquilibriumColor != nil {
+        for _, e := range equilibria {
+            g.DrawEquilibrium(e, style.EquilibriumColor)
+        }
+    }
+
+    return equilibria
+}
+
+func (g *Graph) DrawPhasePortrait(s System, seeds ...*Coord) []*Equilibrium {
+    return g.DrawPhasePortraitWithStyle(s, NewPhasePortraitStyle(g.RelationColor), seeds...)
+}
\ No newline at end of file
@@ -2,14 +2,19 @@ package gograph
 
 import "image/color"
 
+/*
+    A 16-bit color whose channels are not
+    premultiplied by its alpha, the same as
+    color.NRGBA64.
+*/
 type RGBA16 struct {
     R, G, B, A uint16
 }
 
 func (c RGBA16) RGBA() (r, g, b, a uint32) {
-    r = uint32(float64(c.R) * float64(c.A) / 0xFFFF)
-    g = uint32(float64(c.G) * float64(c.A) / 0xFFFF)
-    b = uint32(float64(c.B) * float64(c.A) / 0xFFFF)
+    r = uint32(c.R) * uint32(c.A) / 0xFFFF
+    g = uint32(c.G) * uint32(c.A) / 0xFFFF
+    b = uint32(c.B) * uint32(c.A) / 0xFFFF
     a = uint32(c.A)
 
     return
@@ -23,14 +28,63 @@ func MinInt(a, b int) int {
     return b
 }
 
+func MaxInt(a, b int) int {
+    if a >= b {
+        return a
+    }
+
+    return b
+}
+
+func minUint32(a, b uint32) uint32 {
+    if a <= b {
+        return a
+    }
+
+    return b
+}
+
+/*
+    Draws a new color over an old one. The colors
+    returned by RGBA are premultiplied by their alpha,
+    so this is the Porter-Duff "over" operator, and
+    the result is only opaque if either color is.
+*/
 func BlendColor(old, new color.Color) color.Color {
-    old_r, old_g, old_b, _ := old.RGBA()
+    old_r, old_g, old_b, old_a := old.RGBA()
     new_r, new_g, new_b, new_a := new.RGBA()
 
-    return RGBA16{
-        uint16((new_a * new_r + (0xFFFF - new_a) * old_r) / 0xFFFF),
-        uint16((new_a * new_g + (0xFFFF - new_a) * old_g) / 0xFFFF),
-        uint16((new_a * new_b + (0xFFFF - new_a) * old_b) / 0xFFFF),
-        0xFFFF,
+    inv_a := 0xFFFF - new_a
+    a := minUint32(new_a + old_a * inv_a / 0xFFFF, 0xFFFF)
+
+    /*
+        Colors that aren't properly premultiplied, such as
+        color.RGBA{255, 0, 0, 128}, can have channels larger
+        than their alpha, so keep the result a valid color.
+    */
+    channel := func (new_c, old_c uint32) uint16 {
+        return uint16(minUint32(new_c + old_c * inv_a / 0xFFFF, a))
+    }
+
+    return color.RGBA64{
+        channel(new_r, old_r),
+        channel(new_g, old_g),
+        channel(new_b, old_b),
+        uint16(a),
+    }
+}
+
+/*
+    Scales the opacity of a color, keeping
+    it premultiplied by its new alpha.
+*/
+func ScaleAlpha(col color.Color, scale float64) color.Color {
+    r, g, b, a := col.RGBA()
+
+    return color.RGBA64{
+        uint16(float64(r) * scale),
+        uint16(float64(g) * scale),
+        uint16(float64(b) * scale),
+        uint16(float64(a) * scale),
     }
 }
\ No newline at end of file
@@ -0,0 +1,178 @@
+package gograph
+
+import (
+    "math"
+    "math/rand"
+    "image/color"
+)
+
+const (
+    /* The length in pixels of the sides of arrowheads */
+    ArrowHeadSize = 6.0
+
+    /* The angle between the sides of arrowheads and their shafts */
+    ArrowHeadAngle = math.Pi / 6
+
+    /* How much of the spacing of a slope field its segments take up */
+    SlopeSegmentLength = 0.7
+)
+
+/* The default color of the solutions drawn over slope fields */
+var DefaultSolutionColor = color.RGBA{0x00, 0x00, 0xFF, 0xFF}
+
+/*
+    How a vector field is drawn. Arrows are placed
+    Spacing apart, and are randomly moved around within
+    their spacing if Jitter is set.
+
+    The longest arrow is Scale times the Spacing long,
+    with the other arrows scaled by their magnitude, unless
+    Normalize is set, in which case every arrow is that long.
+
+    If Colormap isn't nil, the arrows are colored by
+    their magnitude, up to MaxMagnitude, or up to the
+    largest magnitude that was drawn if that is 0.
+*/
+type VectorFieldStyle struct {
+    Spacing, Scale float64
+    Jitter, Normalize bool
+
+    Colormap Colormap
+    MaxMagnitude float64
+}
+
+func NewVectorFieldStyle(spacing float64) *VectorFieldStyle {
+    return &VectorFieldStyle{spacing, 0.8, false, false, nil, 0}
+}
+
+/*
+    Draws the head of an arrow pointing towards tip
+    from the direction of tail, with its size in pixels
+    so that it doesn't depend on the scale of the graph.
+*/
+func (g *Graph) DrawArrowHead(tail, tip *Coord, col color.Color) {
+    p_tail, p_tip := g.CoordToSubpixel(tail), g.CoordToSubpixel(tip)
+
+    length := p_tip.Dist(p_tail)
+    if length == 0 || math.IsNaN(length) {
+        return
+    }
+
+    /* Keep the heads of short arrows from being bigger than the arrows */
+    back := p_tail.Sub(p_tip).Mult(math.Min(ArrowHeadSize, length / 2) / length)
+
+    for _, theta := range [2]float64{ArrowHeadAngle, -ArrowHeadAngle} {
+        side := p_tip.Add(back.Rotate(theta))
+
+        g.DrawLine(tip, g.SubpixelToCoord(side.X, side.Y), col)
+    }
+}
+
+func (g *Graph) DrawArrow(tail, tip *Coord, col color.Color) {
+    g.DrawLine(tail, tip, col)
+    g.DrawArrowHead(tail, tip, col)
+}
+
+/* The points that a vector field is sampled at, of which there are none if the spacing isn't positive */
+func (g *Graph) vectorFieldPoints(spacing float64, jitter bool) []*Coord {
+    var points []*Coord
+    if !(spacing > 0) {
+        return points
+    }
+
+    rnd := rand.New(rand.NewSource(1))
+
+    for x := math.Ceil(g.Bounds.Pos0.X / spacing) * spacing; x < g.Bounds.Pos1.X; x += spacing {
+        for y := math.Ceil(g.Bounds.Pos1.Y / spacing) * spacing; y < g.Bounds.Pos0.Y; y += spacing {
+            c := NewCoord(x, y)
+
+            if jitter {
+                c = c.Add(NewCoord(rnd.Float64() - 0.5, rnd.Float64() - 0.5).Mult(spacing))
+            }
+
+            points = append(points, c)
+        }
+    }
+
+    return points
+}
+
+/* Draws arrows showing the direction and magnitude of a vector field */
+func (g *Graph) DrawVectorFieldWithStyle(v VectorField, style *VectorFieldStyle) {
+    points := g.vectorFieldPoints(style.Spacing, style.Jitter)
+    vectors := make([]*Coord, len(points))
+
+    max_mag := 0.0
+    for i, c := range points {
+        vectors[i] = v(c)
+
+        if mag := vectors[i].DistOrigin(); vectors[i].IsValid() {
+            max_mag = math.Max(max_mag, mag)
+        }
+    }
+
+    color_mag := style.MaxMagnitude
+    if color_mag == 0 {
+        color_mag = max_mag
+    }
+
+    length := style.Scale * style.Spacing
+
+    for i, c := range points {
+        vec := vectors[i]
+        mag := vec.DistOrigin()
+
+        if !vec.IsValid() || mag == 0 {
+            continue
+        }
+
+        var col color.Color = g.RelationColor
+        if style.Colormap != nil {
+            col = style.Colormap(mag / color_mag)
+        }
+
+        if style.Normalize {
+            vec = vec.Mult(length / mag)
+        } else {
+            vec = vec.Mult(length / max_mag)
+        }
+
+        g.DrawArrow(c.Sub(vec.Div(2)), c.Add(vec.Div(2)), col)
+    }
+}
+
+/* Draws a vector field with about 20 arrows across the graph */
+func (g *Graph) DrawVectorField(v VectorField) {
+    g.DrawVectorFieldWithStyle(v, NewVectorFieldStyle(g.Bounds.Width() / 20))
+}
+
+/*
+    Draws short segments with the slope of a differential
+    function at points spacing apart, along with the solutions
+    that go through each of the seed coordinates.
+*/
+func (g *Graph) DrawSlopeFieldWithColor(d DifferentialFunction, spacing float64, field_col, solution_col color.Color, seeds ...*Coord) {
+    half := SlopeSegmentLength * spacing / 2
+
+    for _, c := range g.vectorFieldPoints(spacing, false) {
+        slope := d(c)
+        if math.IsNaN(slope) {
+            continue
+        }
+
+        dir := NewCoord(0, 1)
+        if !math.IsInf(slope, 0) {
+            dir = NewCoord(1, slope).Div(math.Hypot(1, slope))
+        }
+
+        g.DrawLine(c.Sub(dir.Mult(half)), c.Add(dir.Mult(half)), field_col)
+    }
+
+    for _, seed := range seeds {
+        g.DrawDifferentialFunctionWithColor(d, seed, solution_col)
+    }
+}
+
+func (g *Graph) DrawSlopeField(d DifferentialFunction, spacing float64, seeds ...*Coord) {
+    g.DrawSlopeFieldWithColor(d, spacing, g.RelationColor, DefaultSolutionColor, seeds...)
+}
\ No newline at end of file
@@ -0,0 +1,242 @@
+package gograph
+
+import (
+    "math"
+    "math/cmplx"
+    "image"
+    "image/color"
+)
+
+/* The most iterations of Newton's method used to invert a ComplexRelation */
+const InverseIterations = 50
+
+/* How the colors between pixels are found */
+type Interpolation int
+
+const (
+    /* Uses the color of the nearest pixel */
+    NearestInterpolation Interpolation = iota
+
+    /* Blends between the 2x2 nearest pixels */
+    BilinearInterpolation
+
+    /*
+        Fits a Catmull-Rom spline through the 4x4 nearest pixels,
+        which is sharper than bilinear interpolation.
+    */
+    BicubicInterpolation
+)
+
+/* Gets a pixel of an image, which is transparent outside of its bounds */
+func pixelAt(img *image.RGBA, x, y int) [4]float64 {
+    if !image.Pt(x, y).In(img.Bounds()) {
+        return [4]float64{}
+    }
+
+    r, g, b, a := img.At(x, y).RGBA()
+
+    return [4]float64{float64(r), float64(g), float64(b), float64(a)}
+}
+
+/* The weights of the Catmull-Rom spline for the 4 pixels around t */
+func cubicWeights(t float64) [4]float64 {
+    t2, t3 := t * t, t * t * t
+
+    return [4]float64 {
+        (-t3 + 2 * t2 - t) / 2,
+        (3 * t3 - 5 * t2 + 2) / 2,
+        (-3 * t3 + 4 * t2 + t) / 2,
+        (t3 - t2) / 2,
+    }
+}
+
+/*
+    Gets the color of an image at a position between its pixels,
+    where each pixel is at its top left corner. Since the colors
+    are premultiplied, transparent pixels don't bleed their color.
+*/
+func SampleImage(img *image.RGBA, x, y float64, interp Interpolation) color.Color {
+    if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+        return color.Transparent
+    }
+
+    var sum [4]float64
+
+    add := func (px, py int, weight float64) {
+        if weight == 0 {
+            return
+        }
+
+        pixel := pixelAt(img, px, py)
+        for i := range sum {
+            sum[i] += pixel[i] * weight
+        }
+    }
+
+    x0, y0 := math.Floor(x), math.Floor(y)
+    tx, ty := x - x0, y - y0
+
+    switch interp {
+        case NearestInterpolation:
+            add(int(math.Round(x)), int(math.Round(y)), 1)
+
+        case BilinearInterpolation:
+            add(int(x0),     int(y0),     (1 - tx) * (1 - ty))
+            add(int(x0) + 1, int(y0),     tx * (1 - ty))
+            add(int(x0),     int(y0) + 1, (1 - tx) * ty)
+            add(int(x0) + 1, int(y0) + 1, tx * ty)
+
+        case BicubicInterpolation:
+            wx, wy := cubicWeights(tx), cubicWeights(ty)
+
+            for i := 0; i < 4; i++ {
+                for j := 0; j < 4; j++ {
+                    add(int(x0) + i - 1, int(y0) + j - 1, wx[i] * wy[j])
+                }
+            }
+    }
+
+    /* Bicubic interpolation can overshoot, so keep the color valid */
+    alpha := math.Max(0, math.Min(0xFFFF, sum[3]))
+
+    channel := func (c float64) uint16 {
+        return uint16(math.Round(math.Max(0, math.Min(alpha, c))))
+    }
+
+    return color.RGBA64{channel(sum[0]), channel(sum[1]), channel(sum[2]), uint16(math.Round(alpha))}
+}
+
+/*
+    Solves rel(z) == w for z with Newton's method, starting
+    from guess, with the derivative found by central differences.
+    The bool is false if the solution couldn't be found.
+*/
+func (rel ComplexRelation) SolveNear(w, guess complex128) (complex128, bool) {
+    z := guess
+
+    for i := 0; i < InverseIterations; i++ {
+        h := complex(1e-6 * math.Max(1, cmplx.Abs(z)), 0)
+
+        diff := rel(z) - w
+        deriv := (rel(z + h) - rel(z - h)) / (2 * h)
+
+        if deriv == 0 || cmplx.IsNaN(diff) || cmplx.IsNaN(deriv) || cmplx.IsInf(deriv) {
+            return 0, false
+        }
+
+        step := diff / deriv
+        z -= step
+
+        if cmplx.IsNaN(z) || cmplx.IsInf(z) {
+            return 0, false
+        }
+
+        if cmplx.Abs(step) <= 1e-10 * math.Max(1, cmplx.Abs(z)) {
+            return z, cmplx.Abs(rel(z) - w) <= 1e-6 * math.Max(1, cmplx.Abs(w))
+        }
+    }
+
+    return 0, false
+}
+
+/*
+    Returns a function that undoes rel by solving for
+    the value that rel maps onto each complex number. Where rel
+    maps more than one value to the same place, which of them
+    is found depends on where Newton's method starts, which is
+    the previous value found if it is given, and then the
+    complex number itself. Where no value is found, NaN is returned.
+*/
+func (rel ComplexRelation) inverse() func (w complex128, previous *complex128) complex128 {
+    return func (w complex128, previous *complex128) complex128 {
+        if previous != nil && !cmplx.IsNaN(*previous) {
+            if z, ok := rel.SolveNear(w, *previous); ok {
+                return z
+            }
+        }
+
+        if z, ok := rel.SolveNear(w, w); ok {
+            return z
+        }
+
+        return cmplx.NaN()
+    }
+}
+
+func (g *Graph) applyInverseComplexRelationInChunk(inverse func (w complex128, previous *complex128) complex128, src, dst *image.RGBA, interp Interpolation, r *image.Rectangle, ch chan struct{}) {
+    for x := r.Min.X; x < r.Max.X; x++ {
+        var previous *complex128
+
+        for y := r.Min.Y; y < r.Max.Y; y++ {
+            /* Map from the center of each pixel */
+            c := g.SubpixelToCoord(float64(x) + 0.5, float64(y) + 0.5)
+
+            z := inverse(complex(c.X, c.Y), previous)
+            previous = &z
+
+            src_c := g.CoordToSubpixel(NewCoord(real(z), imag(z)))
+
+            dst.Set(x, y, SampleImage(src, src_c.X - 0.5, src_c.Y - 0.5, interp))
+        }
+    }
+
+    ch <- struct{}{}
+}
+
+func (g *Graph) applyInverseComplexRelation(inverse func (w complex128, previous *complex128) complex128, interp Interpolation) {
+    src := g.Image
+    dst := image.NewRGBA(src.Bounds())
+
+    var channels []chan struct{}
+
+    for x := 0; x < g.ImageWidth(); x += ChunkSize {
+        for y := 0; y < g.ImageHeight(); y += ChunkSize {
+            ch := make(chan struct{})
+            channels = append(channels, ch)
+
+            r := image.Rect(x, y, MinInt(x + ChunkSize, g.ImageWidth()), MinInt(y + ChunkSize, g.ImageHeight()))
+            go g.applyInverseComplexRelationInChunk(inverse, src, dst, interp, &r, ch)
+        }
+    }
+
+    for _, ch := range channels {
+        <-ch
+    }
+
+    g.Image = dst
+    g.layer.Image = dst
+}
+
+/*
+    Like ApplyComplexRelation, but instead of moving each pixel to
+    where it is mapped, it goes through each pixel and finds the color
+    of what would be mapped onto it using inverse, the inverse of the
+    mapping, so that stretched parts don't leave holes or speckles.
+    Each chunk only writes its own pixels, and the colors are found
+    between pixels of the image as it was before with interp.
+*/
+func (g *Graph) ApplyInverseComplexRelationWithInterpolation(inverse ComplexRelation, interp Interpolation) {
+    g.applyInverseComplexRelation(func (w complex128, previous *complex128) complex128 {
+        return inverse(w)
+    }, interp)
+}
+
+func (g *Graph) ApplyInverseComplexRelation(inverse ComplexRelation) {
+    g.ApplyInverseComplexRelationWithInterpolation(inverse, BilinearInterpolation)
+}
+
+/*
+    Like ApplyInverseComplexRelationWithInterpolation, but for when
+    the inverse isn't known, in which case it is solved for with
+    Newton's method. Where rel maps more than one value to the same
+    place, the value found is the one nearest to the value found for
+    the pixel above, or failing that, to the pixel itself. This is
+    much slower than giving the inverse.
+*/
+func (g *Graph) ApplyComplexRelationInvertedWithInterpolation(rel ComplexRelation, interp Interpolation) {
+    g.applyInverseComplexRelation(rel.inverse(), interp)
+}
+
+func (g *Graph) ApplyComplexRelationInverted(rel ComplexRelation) {
+    g.ApplyComplexRelationInvertedWithInterpolation(rel, BilinearInterpolation)
+}
\ No newline at end of file